@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/yourusername/yourprojectname/db/migration"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// modulesFile is where the generated module is registered
+const modulesFile = "cmd/server/commands.go"
+
+// catalogDir holds the message catalogs, one JSON file per locale
+const catalogDir = "internal/i18n/locales"
+
+// defaultCatalog is the catalog whose translations are the messages themselves
+const defaultCatalog = "en.json"
+
+// untranslated marks the English text the generator puts into the other catalogs
+const untranslated = "TODO(translate) "
+
+// registerCall is followed by the module list in modulesFile
+const registerCall = "return a.Register(\n"
+
+// generator writes the files of a resource below root
+type generator struct {
+	root   string
+	dryRun bool
+	force  bool
+}
+
+// output is a file to generate: path relative to the root and the template rendering it
+type output struct {
+	path     string
+	template string
+}
+
+func (g *generator) generate(res resource) error {
+	migrationDir := filepath.Join(g.root, "db", "migration")
+	if err := g.checkNoMigration(migrationDir, res); err != nil {
+		return err
+	}
+	res.Version = fmt.Sprintf("%06d", migration.LatestVersion(os.DirFS(migrationDir))+1)
+
+	files := outputs(res)
+
+	// Render everything before writing anything, so a template error leaves the tree untouched
+	contents := make([][]byte, len(files))
+	for i, out := range files {
+		if !g.force {
+			if _, err := os.Stat(filepath.Join(g.root, out.path)); err == nil {
+				return fmt.Errorf("%s already exists; use --force to overwrite it", out.path)
+			}
+		}
+		content, err := render(out, res)
+		if err != nil {
+			return err
+		}
+		contents[i] = content
+	}
+
+	for i, out := range files {
+		if g.dryRun {
+			fmt.Printf("--- %s\n%s\n", out.path, contents[i])
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(g.root, out.path), contents[i], 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", out.path)
+	}
+	if err := g.addMessages(res); err != nil {
+		return err
+	}
+	return g.registerModule(res)
+}
+
+// outputs lists the files of res; its Version must be set
+func outputs(res resource) []output {
+	migrationName := fmt.Sprintf("%s_create_%s_table", res.Version, res.Table)
+	return []output{
+		{path: "db/migration/" + migrationName + ".up.sql", template: "migration.up.sql.tmpl"},
+		{path: "db/migration/" + migrationName + ".down.sql", template: "migration.down.sql.tmpl"},
+		{path: "db/queries/" + res.Snake + ".sql", template: "queries.sql.tmpl"},
+		{path: "internal/repository/" + res.Snake + "_repository.go", template: "repository.go.tmpl"},
+		{path: "internal/service/" + res.Snake + "_service.go", template: "service.go.tmpl"},
+		{path: "internal/service/" + res.Snake + "_service_test.go", template: "service_test.go.tmpl"},
+		{path: "internal/handler/" + res.Snake + "_handler.go", template: "handler.go.tmpl"},
+		{path: "internal/handler/" + res.Snake + "_handler_test.go", template: "handler_test.go.tmpl"},
+		{path: "internal/router/" + res.Snake + "_router.go", template: "router.go.tmpl"},
+		{path: "internal/modules/" + res.Snake + ".go", template: "module.go.tmpl"},
+	}
+}
+
+// checkNoMigration refuses to create a second migration for the same table
+func (g *generator) checkNoMigration(dir string, res resource) error {
+	matches, err := fs.Glob(os.DirFS(dir), "*_create_"+res.Table+"_table.up.sql")
+	if err != nil {
+		return err
+	}
+	if len(matches) > 0 && !g.force {
+		return fmt.Errorf("db/migration/%s already creates table %s", matches[0], res.Table)
+	}
+	return nil
+}
+
+// render executes the template of out; Go files are formatted
+func render(out output, res resource) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, out.template, res); err != nil {
+		return nil, fmt.Errorf("render %s: %w", out.path, err)
+	}
+	if !strings.HasSuffix(out.path, ".go") {
+		return buf.Bytes(), nil
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", out.path, err)
+	}
+	return formatted, nil
+}
+
+// addMessages adds the messages of the resource to every catalog, since i18n.Load rejects catalogs
+// lacking a message of en.json. The other locales get the English text marked as untranslated.
+func (g *generator) addMessages(res resource) error {
+	dir := filepath.Join(g.root, catalogDir)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Printf("Could not find the catalogs in %s; add the messages %q to each\n", catalogDir, res.Messages())
+		return nil
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		content, added, err := addToCatalog(path, res.Messages(), name != defaultCatalog)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", catalogDir, name, err)
+		}
+		if len(added) == 0 {
+			continue
+		}
+		if g.dryRun {
+			fmt.Printf("--- %s/%s\n", catalogDir, name)
+			for _, msg := range added {
+				fmt.Printf("+%q\n", msg)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("added %d messages to %s/%s\n", len(added), catalogDir, name)
+	}
+	return nil
+}
+
+// addToCatalog returns the catalog at path with the missing messages added and the messages it added.
+// The catalogs are written like encoding/json indents them, so existing entries keep their lines.
+func addToCatalog(path string, messages []string, markUntranslated bool) ([]byte, []string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var catalog map[string]string
+	if err := json.Unmarshal(src, &catalog); err != nil {
+		return nil, nil, err
+	}
+	var added []string
+	for _, msg := range messages {
+		if _, ok := catalog[msg]; ok {
+			continue
+		}
+		translation := msg
+		if markUntranslated {
+			translation = untranslated + msg
+		}
+		catalog[msg] = translation
+		added = append(added, msg)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(catalog); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), added, nil
+}
+
+// registerModule adds the module to the list passed to a.Register in registerModules
+func (g *generator) registerModule(res resource) error {
+	line := fmt.Sprintf("\t\tmodules.New%sModule(),\n", res.Go)
+	hint := fmt.Sprintf("register modules.New%sModule() with a.Register in registerModules of %s", res.Go, modulesFile)
+
+	path := filepath.Join(g.root, modulesFile)
+	src, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("Could not find", modulesFile+";", hint)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	content := string(src)
+	if strings.Contains(content, strings.TrimSpace(line)) {
+		return nil
+	}
+	start := strings.Index(content, registerCall)
+	end := -1
+	if start >= 0 {
+		end = strings.Index(content[start:], "\n\t)")
+	}
+	if end < 0 {
+		fmt.Println("Could not find the module list;", hint)
+		return nil
+	}
+	insertAt := start + end + 1
+	content = content[:insertAt] + line + content[insertAt:]
+
+	if g.dryRun {
+		fmt.Printf("--- %s\n+%s", modulesFile, line)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("registered the module in", modulesFile)
+	return nil
+}
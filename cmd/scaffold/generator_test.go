@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the rendered templates")
+
+func newTestResource(t *testing.T) resource {
+	t.Helper()
+	res, err := newResource("blog_post", "title:string,body:text,views:int,published:bool,published_at:time")
+	if err != nil {
+		t.Fatalf("newResource: %v", err)
+	}
+	res.Version = "000042"
+	return res
+}
+
+// TestRender_Golden compares every generated file with testdata/blog_post; run with -update after changing a template
+func TestRender_Golden(t *testing.T) {
+	res := newTestResource(t)
+
+	for _, out := range outputs(res) {
+		t.Run(filepath.Base(out.path), func(t *testing.T) {
+			got, err := render(out, res)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			golden := filepath.Join("testdata", res.Snake, filepath.Base(out.path)+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading the golden file: %v; run go test ./cmd/scaffold -update", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s; run go test ./cmd/scaffold -update and review the diff", out.path, golden)
+			}
+		})
+	}
+}
+
+// TestRender_MessagesAreDerivedFromTheLabel guards Messages against drifting from what the templates use
+func TestRender_MessagesAreDerivedFromTheLabel(t *testing.T) {
+	res := newTestResource(t)
+
+	var rendered strings.Builder
+	for _, out := range outputs(res) {
+		content, err := render(out, res)
+		if err != nil {
+			t.Fatalf("render %s: %v", out.path, err)
+		}
+		rendered.Write(content)
+	}
+	for _, call := range []string{`translateError(err, "blog post")`, `parseIDParam(c, "id", "blog post")`} {
+		if !strings.Contains(rendered.String(), call) {
+			t.Errorf("the generated code does not call %s, update resource.Messages", call)
+		}
+	}
+}
+
+func TestAddMessages(t *testing.T) {
+	res := newTestResource(t)
+	root := t.TempDir()
+	dir := filepath.Join(root, catalogDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	catalogs := map[string]string{
+		"en.json": "{\n  \"blog post not found\": \"blog post not found\",\n  \"user not found\": \"user not found\"\n}\n",
+		"de.json": "{\n  \"blog post not found\": \"Blogbeitrag nicht gefunden\",\n  \"user not found\": \"Benutzer nicht gefunden\"\n}\n",
+	}
+	for name, content := range catalogs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &generator{root: root}
+	if err := g.addMessages(res); err != nil {
+		t.Fatalf("addMessages: %v", err)
+	}
+	tests := []struct {
+		catalog string
+		msg     string
+		want    string
+	}{
+		{catalog: "en.json", msg: "user not found", want: "user not found"},
+		{catalog: "en.json", msg: "blog post not found", want: "blog post not found"},
+		{catalog: "en.json", msg: "invalid blog post: %s", want: "invalid blog post: %s"},
+		{catalog: "de.json", msg: "blog post not found", want: "Blogbeitrag nicht gefunden"},
+		{catalog: "de.json", msg: "invalid blog post: %s", want: "TODO(translate) invalid blog post: %s"},
+		{catalog: "de.json", msg: "invalid blog post ID format", want: "TODO(translate) invalid blog post ID format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.catalog+" "+tt.msg, func(t *testing.T) {
+			catalog := readCatalog(t, filepath.Join(dir, tt.catalog))
+			if got := catalog[tt.msg]; got != tt.want {
+				t.Errorf("%s[%q] = %q, want %q", tt.catalog, tt.msg, got, tt.want)
+			}
+		})
+	}
+	for name := range catalogs {
+		if got := len(readCatalog(t, filepath.Join(dir, name))); got != 1+len(res.Messages()) {
+			t.Errorf("%s has %d messages, want %d", name, got, 1+len(res.Messages()))
+		}
+	}
+
+	// A second run leaves the catalogs as they are
+	before, _ := os.ReadFile(filepath.Join(dir, "de.json"))
+	if err := g.addMessages(res); err != nil {
+		t.Fatalf("second addMessages: %v", err)
+	}
+	if after, _ := os.ReadFile(filepath.Join(dir, "de.json")); !bytes.Equal(before, after) {
+		t.Errorf("a second run changed de.json:\n%s", after)
+	}
+}
+
+func TestAddToCatalog_KeepsTheFormatOfTheCatalogs(t *testing.T) {
+	for _, name := range []string{"en.json", "de.json", "fr.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("..", "..", catalogDir, name)
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, added, err := addToCatalog(path, nil, name != defaultCatalog)
+			if err != nil {
+				t.Fatalf("addToCatalog: %v", err)
+			}
+			if len(added) != 0 || !bytes.Equal(content, src) {
+				t.Errorf("rewriting %s without new messages changed it", name)
+			}
+		})
+	}
+}
+
+func readCatalog(t *testing.T, path string) map[string]string {
+	t.Helper()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var catalog map[string]string
+	if err := json.Unmarshal(src, &catalog); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return catalog
+}
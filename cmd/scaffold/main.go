@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// scaffold generates the files of a new resource following the conventions of the user resource.
+//
+//	go run ./cmd/scaffold resource <name> --fields title:string,price:float,published:bool
+//
+// Exit status: 0 on success, 1 when generation failed, 2 on invalid usage.
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "resource" {
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("resource", flag.ExitOnError)
+	fs.Usage = usage
+	fields := fs.String("fields", "", "comma-separated name:type list; types: "+fieldTypeNames())
+	dir := fs.String("dir", ".", "root of the repository")
+	dryRun := fs.Bool("dry-run", false, "print the files that would be written without writing them")
+	force := fs.Bool("force", false, "overwrite existing files")
+	// The resource name comes before the flags, which flag.Parse would otherwise stop at
+	args := os.Args[2:]
+	if len(args) == 0 || args[0] == "" || args[0][0] == '-' {
+		usage()
+		os.Exit(2)
+	}
+	name := args[0]
+	_ = fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments: %v\n", fs.Args())
+		os.Exit(2)
+	}
+
+	res, err := newResource(name, *fields)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "scaffold:", err)
+		os.Exit(2)
+	}
+	g := &generator{root: *dir, dryRun: *dryRun, force: *force}
+	if err := g.generate(res); err != nil {
+		fmt.Fprintln(os.Stderr, "scaffold:", err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		return
+	}
+	fmt.Printf(`
+Next steps:
+  1. Review the migration and adjust column types, defaults and indexes
+  2. Regenerate the queries:  sqlc generate
+  3. Apply the migration:     make migrate-up
+  4. Translate the messages marked %s in %s
+  5. Fill in the test skeletons and run: go test ./internal/...
+`, strings.TrimSpace(untranslated), catalogDir)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: scaffold resource <name> --fields name:type[,name:type...] [--dir .] [--dry-run] [--force]
+
+<name> is the singular resource name in snake_case, e.g. blog_post.
+Field types: %s.
+id, created_at and updated_at are added to every table.
+`, fieldTypeNames())
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// fieldType maps a --fields type to its column, its Go type in sqlc models and the pgtype used
+// for the optional fields of updates.
+type fieldType struct {
+	SQL       string
+	Go        string
+	Null      string // e.g. pgtype.Text
+	NullValue string // field of Null holding the value, e.g. String
+	Binding   string // validation of create requests
+	Sample    string // JSON value used by the test skeletons
+}
+
+var fieldTypes = map[string]fieldType{
+	"string": {SQL: "VARCHAR(255) NOT NULL", Go: "string", Null: "pgtype.Text", NullValue: "String", Binding: "required,max=255", Sample: `"example"`},
+	"text":   {SQL: "TEXT NOT NULL", Go: "string", Null: "pgtype.Text", NullValue: "String", Binding: "required", Sample: `"example"`},
+	"int":    {SQL: "BIGINT NOT NULL", Go: "int64", Null: "pgtype.Int8", NullValue: "Int64", Sample: "1"},
+	"float":  {SQL: "DOUBLE PRECISION NOT NULL", Go: "float64", Null: "pgtype.Float8", NullValue: "Float64", Sample: "1.5"},
+	"bool":   {SQL: "BOOLEAN NOT NULL DEFAULT FALSE", Go: "bool", Null: "pgtype.Bool", NullValue: "Bool", Sample: "true"},
+	"time":   {SQL: "TIMESTAMPTZ NOT NULL", Go: "time.Time", Null: "pgtype.Timestamptz", NullValue: "Time", Sample: `"2024-01-01T00:00:00Z"`},
+}
+
+func fieldTypeNames() string {
+	names := make([]string, 0, len(fieldTypes))
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// field is a column of the resource
+type field struct {
+	Snake string // column and JSON name, e.g. due_at
+	Go    string // struct field name as sqlc derives it, e.g. DueAt
+	Var   string // parameter name as sqlc derives it, e.g. dueAt
+	Type  fieldType
+}
+
+// IsTime reports whether the field is rendered as an RFC 3339 string in responses
+func (f field) IsTime() bool {
+	return f.Type.Go == "time.Time"
+}
+
+// resource holds the names a resource appears under in the generated files
+type resource struct {
+	Snake    string // blog_post: file names, audit actions
+	Table    string // blog_posts
+	Go       string // BlogPost: sqlc model and Go identifiers
+	GoPlural string // BlogPosts
+	Var      string // blogPost
+	Path     string // blog-posts: URL segment
+	Label    string // blog post: messages and comments
+	Fields   []field
+
+	Version string // number of the migration, set by the generator
+}
+
+// MultiField reports whether sqlc generates a Create...Params struct; for one column it takes the value directly
+func (r resource) MultiField() bool {
+	return len(r.Fields) > 1
+}
+
+// Messages returns the client-facing messages of the generated code: those parseIDParam and
+// translateError derive from the label, which every catalog must contain
+func (r resource) Messages() []string {
+	return []string{
+		r.Label + " not found",
+		r.Label + " already exists",
+		r.Label + " references a record that does not exist or is still referenced",
+		"invalid " + r.Label + ": %s",
+		"invalid " + r.Label + " ID format",
+	}
+}
+
+// HasTime reports whether a field needs the time package
+func (r resource) HasTime() bool {
+	for _, f := range r.Fields {
+		if f.IsTime() {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleJSON is a valid create request for the test skeletons
+func (r resource) SampleJSON() string {
+	parts := make([]string, 0, len(r.Fields))
+	for _, f := range r.Fields {
+		parts = append(parts, fmt.Sprintf("%q: %s", f.Snake, f.Type.Sample))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// reservedColumns are added to every table
+var reservedColumns = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+// packageNames are imported by the generated files, so variables named like the resource must not shadow them
+var packageNames = map[string]bool{
+	"app": true, "context": true, "errors": true, "gin": true, "handler": true, "http": true, "pgtype": true,
+	"repository": true, "router": true, "service": true, "sqlc": true, "strings": true, "testing": true, "time": true,
+}
+
+// newResource validates the name and the --fields specification
+func newResource(name, fieldSpec string) (resource, error) {
+	if !identifier.MatchString(name) {
+		return resource{}, fmt.Errorf("resource name %q must be snake_case, e.g. blog_post", name)
+	}
+	if packageNames[varName(name)] || token.IsKeyword(varName(name)) {
+		return resource{}, fmt.Errorf("resource name %q collides with a Go package or keyword used by the generated code", name)
+	}
+	table := pluralize(name)
+	res := resource{
+		Snake:    name,
+		Table:    table,
+		Go:       goName(name),
+		GoPlural: goName(table),
+		Var:      varName(name),
+		Path:     strings.ReplaceAll(table, "_", "-"),
+		Label:    strings.ReplaceAll(name, "_", " "),
+	}
+	if fieldSpec == "" {
+		return resource{}, fmt.Errorf("--fields is required, e.g. --fields title:string,published:bool")
+	}
+	seen := make(map[string]bool)
+	for _, spec := range strings.Split(fieldSpec, ",") {
+		fieldName, typeName, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok {
+			return resource{}, fmt.Errorf("field %q must be name:type", spec)
+		}
+		typ, ok := fieldTypes[typeName]
+		if !ok {
+			return resource{}, fmt.Errorf("field %s: unknown type %q, expected one of %s", fieldName, typeName, fieldTypeNames())
+		}
+		switch {
+		case !identifier.MatchString(fieldName):
+			return resource{}, fmt.Errorf("field name %q must be snake_case", fieldName)
+		case reservedColumns[fieldName]:
+			return resource{}, fmt.Errorf("field %s is added to every table", fieldName)
+		case token.IsKeyword(varName(fieldName)):
+			return resource{}, fmt.Errorf("field name %q is a Go keyword", fieldName)
+		case seen[fieldName]:
+			return resource{}, fmt.Errorf("field %s is given twice", fieldName)
+		}
+		seen[fieldName] = true
+		res.Fields = append(res.Fields, field{Snake: fieldName, Go: goName(fieldName), Var: varName(fieldName), Type: typ})
+	}
+	return res, nil
+}
+
+// goName converts snake_case like sqlc does: each part is capitalized, and "id" becomes "ID"
+func goName(snake string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		if part == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// varName is goName with a lower-case first part, as sqlc names single parameters
+func varName(snake string) string {
+	first, rest, _ := strings.Cut(snake, "_")
+	if rest == "" {
+		return first
+	}
+	return first + goName(rest)
+}
+
+// pluralize covers the regular English plurals; sqlc singularizes table names back into model names
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+	return word + "s"
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/db/migration"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/health"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/lifecycle"
+	"github.com/yourusername/yourprojectname/internal/metrics"
+	"github.com/yourusername/yourprojectname/internal/modules"
+)
+
+// programName is how the binary is called in usage messages
+const programName = "app"
+
+// command is a subcommand of the binary, e.g. "app migrate up"
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", summary: "run the API and admin servers (the default)", run: serve},
+	{name: "migrate", summary: "apply or roll back database migrations: up, down, status, force", run: migrateCommand},
+	{name: "seed", summary: "insert sample users for development", run: seedCommand},
+	{name: "user", summary: "manage users: create, reset-password", run: userCommand},
+	{name: "config", summary: "check the configuration: validate", run: configCommand},
+}
+
+// errFlags reports invalid flags, which the flag package has already printed along with the usage
+var errFlags = errors.New("invalid flags")
+
+// usageError is invalid usage other than flags, such as a missing argument
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// execute runs the command named by the first argument, serve when there is none or it is a flag,
+// and returns the exit status: 0 on success, 1 when the command failed, 2 on invalid usage.
+func execute(args []string) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return 0
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "%s: unknown command %q\n\n", programName, name)
+		usage()
+		return 2
+	}
+
+	err := cmd.run(args)
+	var usageErr usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errFlags):
+		return 2
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "%s %s: %v\nRun '%s %s -h' for usage.\n", programName, name, err, programName, name)
+		return 2
+	case name == "serve":
+		slog.Error("Server failed", slog.Any("error", err))
+		return 1
+	default:
+		fmt.Fprintf(os.Stderr, "%s %s: %v\n", programName, name, err)
+		return 1
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n\nCommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, `
+Every command loads the configuration like the server does, from the config file, the environment
+and flags such as -config app.yaml or -db.url; "%s serve -h" lists them.
+`, programName)
+}
+
+// parseArgs registers the configuration flags on fs, which already holds the flags of the command, and parses args.
+// The usage lists only the flags of the command, except for serve, which has none of its own.
+func parseArgs(fs *flag.FlagSet, synopsis string, args []string) (*config.Loader, error) {
+	var own []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { own = append(own, f) })
+	loader := config.NewLoader(fs)
+
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "usage: %s %s\n", programName, synopsis)
+		if len(own) == 0 {
+			fs.PrintDefaults()
+			return
+		}
+		ownFlags := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+		ownFlags.SetOutput(out)
+		for _, f := range own {
+			ownFlags.Var(f.Value, f.Name, f.Usage)
+			ownFlags.Lookup(f.Name).DefValue = f.DefValue
+		}
+		ownFlags.PrintDefaults()
+		fmt.Fprintf(out, "  plus the configuration flags, see %s serve -h\n", programName)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, errFlags
+	}
+	return loader, nil
+}
+
+// subcommand splits off the first argument, one of names; -h prints the synopsis
+func subcommand(args []string, synopsis string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usageErrorf("missing subcommand: %s", strings.Join(names, ", "))
+	}
+	switch args[0] {
+	case "-h", "-help", "--help":
+		fmt.Fprintf(os.Stderr, "usage: %s %s\n", programName, synopsis)
+		return "", nil, flag.ErrHelp
+	}
+	if !slices.Contains(names, args[0]) {
+		return "", nil, usageErrorf("unknown subcommand %q, expected one of: %s", args[0], strings.Join(names, ", "))
+	}
+	return args[0], args[1:], nil
+}
+
+// loadConfig parses args like parseArgs, loads the configuration and sets up logging for a command other than serve.
+func loadConfig(fs *flag.FlagSet, synopsis string, args []string) (*config.Config, *config.Loader, error) {
+	loader, err := parseArgs(fs, synopsis, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	setupLogger(cfg)
+	return cfg, loader, nil
+}
+
+// signalContext is cancelled by SIGINT or SIGTERM, so a command stops between steps instead of being killed midway
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// registerModules adds the schema of the built-in modules and registers them, as serve runs them
+func registerModules(a *app.App) error {
+	// golang-migrate numbers versions across all modules
+	a.AddMigrations(migration.FS)
+	// Modules use the services of those before them: audit and users come first
+	return a.Register(
+		modules.NewAuditModule(),
+		modules.NewUsersModule(),
+		modules.NewEmailChangeModule(),
+		modules.NewPrivacyModule(),
+		modules.NewUserImportModule(),
+		modules.NewBlobsModule(),
+	)
+}
+
+// moduleMigrations returns the migrations of every module, the same serve expects to be applied.
+// Modules touch no connection while registering, so none is opened.
+func moduleMigrations(cfg *config.Config, loader *config.Loader) (fs.FS, error) {
+	application := app.New(app.Deps{
+		Config:    cfg,
+		Watcher:   config.NewWatcher(loader, cfg),
+		Metrics:   metrics.NewRegistry(),
+		Health:    health.NewRegistry(cfg.Health.CheckTimeout),
+		Lifecycle: lifecycle.New(),
+	})
+	if err := registerModules(application); err != nil {
+		return nil, err
+	}
+	return application.Migrations(), nil
+}
+
+// openApp connects to the database and registers the modules the user commands need, so they run through
+// the same repositories and services as the API, audit log included. Call the returned function to close it.
+func openApp(cfg *config.Config, loader *config.Loader) (*app.App, func(), error) {
+	dbPool, err := initDB(cfg.DB, cfg.Secret("db.url"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	i18nBundle, err := i18n.Load()
+	if err != nil {
+		dbPool.Close()
+		return nil, nil, fmt.Errorf("failed to load message catalogs: %w", err)
+	}
+
+	// No servers or background jobs run, so the lifecycle is never started
+	application := app.New(app.Deps{
+		Config:    cfg,
+		Watcher:   config.NewWatcher(loader, cfg),
+		DB:        dbPool,
+		I18n:      i18nBundle,
+		Metrics:   metrics.NewRegistry(),
+		Health:    health.NewRegistry(cfg.Health.CheckTimeout),
+		Lifecycle: lifecycle.New(),
+	})
+	if err := application.Register(modules.NewAuditModule(), modules.NewUsersModule()); err != nil {
+		dbPool.Close()
+		return nil, nil, err
+	}
+	return application, dbPool.Close, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const configSynopsis = `config validate [flags]
+
+Loads the configuration like serve does and reports every problem, including the production checks
+serve refuses to start with. Referenced secrets are fetched, but no other service is contacted.`
+
+// configCommand checks a configuration before it is deployed
+func configCommand(args []string) error {
+	_, args, err := subcommand(args, configSynopsis, "validate")
+	if err != nil {
+		return err
+	}
+	return validateConfig(args)
+}
+
+func validateConfig(args []string) error {
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	printConfig := flags.Bool("print", false, "print the effective configuration as JSON, with secrets redacted")
+	cfg, _, err := loadConfig(flags, configSynopsis, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("config validate takes no arguments")
+	}
+
+	if err := cfg.CheckProduction(); err != nil {
+		if !cfg.AllowInsecure {
+			return fmt.Errorf("serve would refuse to start with this production configuration (set ALLOW_INSECURE=true to override): %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: insecure production configuration, allowed by ALLOW_INSECURE: %v\n", err)
+	}
+	if *printConfig {
+		out, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	}
+	fmt.Fprintf(os.Stderr, "Configuration is valid (env %s)\n", cfg.AppEnv)
+	return nil
+}
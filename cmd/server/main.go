@@ -3,119 +3,342 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/yourusername/yourprojectname/config"
-	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/db/migration"
+	"github.com/yourusername/yourprojectname/internal/admin"
+	"github.com/yourusername/yourprojectname/internal/app"
 	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/health"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/lifecycle"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/mailer"
+	"github.com/yourusername/yourprojectname/internal/metrics"
+	"github.com/yourusername/yourprojectname/internal/middleware"
 	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/requestid"
 	app_router "github.com/yourusername/yourprojectname/internal/router"
-	"github.com/yourusername/yourprojectname/internal/service"
+	"github.com/yourusername/yourprojectname/internal/storage"
+	"github.com/yourusername/yourprojectname/internal/tracing"
 )
 
 func main() {
+	os.Exit(execute(os.Args[1:]))
+}
+
+// serve starts the server and blocks until it is stopped by a signal or fails. Everything opened
+// is registered with the lifecycle manager, so it is closed in reverse order on any return.
+func serve(args []string) (err error) {
 	// Load configuration
-	cfg, err := config.LoadConfig()
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	loader, err := parseArgs(fs, "serve [flags]", args)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return err
 	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger, logLevel := setupLogger(cfg)
+	slog.Info("Configuration loaded", slog.String("env", cfg.AppEnv), slog.Int("port", cfg.Server.Port))
 
-	log.Printf("Configuration loaded successfully. App Env: %s, Server: %d", cfg.AppEnv, cfg.AppPort)
+	lc := lifecycle.New()
+	defer func() {
+		// The drain period counts against the stop deadline, so it extends the shutdown timeout
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.DrainPeriod+cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if stopErr := lc.Stop(ctx); stopErr != nil {
+			err = errors.Join(err, stopErr)
+		}
+		slog.Info("Server exiting")
+	}()
 
-	dbPool, err := initDB(cfg.DbURL)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		Environment: cfg.AppEnv,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		return fmt.Errorf("failed to initialize tracing: %w", err)
 	}
-	defer dbPool.Close()
-	log.Println("Database connection pool established.")
+	// Stopped last, so spans of the shutdown are flushed too
+	lc.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
+	slog.Info("Tracing initialized", slog.String("exporter", cfg.Tracing.Exporter))
 
-	rdb, err := initRedis(cfg.RedisURL)
+	if err := cfg.CheckProduction(); err != nil {
+		if !cfg.AllowInsecure {
+			return fmt.Errorf("refusing to start with an insecure production configuration (set ALLOW_INSECURE=true to override): %w", err)
+		}
+		slog.Warn("Starting with an insecure production configuration because ALLOW_INSECURE is set", slog.Any("error", err))
+	}
+
+	dbPool, err := initDB(cfg.DB, cfg.Secret("db.url"))
 	if err != nil {
-		log.Fatalf("Failed to initialize Redis: %v", err)
+		return fmt.Errorf("failed to initialize database: %w", err)
 	}
-	defer rdb.Close()
-	log.Println("Redis client initialized.")
+	lc.Append(lifecycle.Hook{Name: "database pool", OnStop: func(context.Context) error {
+		dbPool.Close()
+		return nil
+	}})
+	slog.Info("Database connection pool established")
 
-	sqlcQuerier := sqlc.New(dbPool)
-	log.Println("SQLC Querier initialized.")
+	rdb, err := initRedis(cfg.Redis)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Redis: %w", err)
+	}
+	lc.Append(lifecycle.Hook{Name: "Redis client", OnStop: func(context.Context) error {
+		return rdb.Close()
+	}})
+	slog.Info("Redis client initialized")
 
-	userRepo := repository.NewDBUserRepository(sqlcQuerier)
-	log.Println("User repository initialized.")
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.AddReadinessCheck("postgres", health.CheckerFunc(dbPool.Ping))
+	healthRegistry.AddReadinessCheck("redis", health.CheckerFunc(func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}))
+	slog.Info("Health checks initialized")
 
-	// Initialize Services
-	userService := service.NewUserService(userRepo) // Example
-	log.Println("User service initialized.")
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.MustRegister(metrics.NewPgxPoolCollector(dbPool), metrics.NewRedisPoolCollector(rdb))
+	slog.Info("Metrics registry initialized")
 
-	// Initialize Gin router
-	if cfg.AppEnv == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	blobStore, err := initBlobStore(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize blob store: %w", err)
 	}
-	router := gin.Default()
+	slog.Info("Blob store initialized", slog.String("store", cfg.Blob.Store))
 
-	// Initialize Handlers
-	userHandler := handler.NewUserHandler(userService)
-	log.Println("User handler initialized.")
+	appMailer, err := initMailer(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mailer: %w", err)
+	}
+	slog.Info("Mailer initialized", slog.String("driver", cfg.Mail.Driver))
+
+	i18nBundle, err := i18n.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load message catalogs: %w", err)
+	}
+	slog.Info("Message catalogs loaded", slog.String("locales", strings.Join(i18nBundle.Locales(), ", ")))
+
+	// Background workers run from lc.Start until after the API server stopped, so in-flight requests can still queue work
+
+	// Referenced secrets (vault:, file:, env: or *_FILE) are fetched again periodically; consumers read them per use
+	lc.Append(lifecycle.Worker("secret refresh", func(ctx context.Context) {
+		cfg.WatchSecrets(ctx, func(changed []string, err error) {
+			if err != nil {
+				slog.Error("Failed to refresh secrets, keeping the previous values", slog.Any("error", err))
+			}
+			if len(changed) > 0 {
+				slog.Info("Secrets rotated", slog.String("keys", strings.Join(changed, ", ")))
+			}
+		})
+	}))
+
+	// Settings tagged `reload` in config are applied through these subscriptions on SIGHUP or config file change
+	configWatcher := config.NewWatcher(loader, cfg)
+	config.Subscribe(configWatcher, func(c *config.Config) string { return c.Log.Level }, func(level string) {
+		logLevel.Set(parseLogLevel(level))
+		slog.Info("Log level changed", slog.String("level", level))
+	})
+
+	application := app.New(app.Deps{
+		Config:    cfg,
+		Watcher:   configWatcher,
+		DB:        dbPool,
+		Redis:     rdb,
+		BlobStore: blobStore,
+		Mailer:    appMailer,
+		I18n:      i18nBundle,
+		Metrics:   metricsRegistry,
+		Health:    healthRegistry,
+		Lifecycle: lc,
+	})
+	if err := registerModules(application); err != nil {
+		return err
+	}
+	healthRegistry.AddStartupCheck("migrations", health.MigrationChecker(dbPool, migration.LatestVersion(application.Migrations())))
+	userRepo, err := app.Get[repository.UserRepository](application)
+	if err != nil {
+		return err
+	}
+
+	// Initialize Gin router
+	gin.SetMode(cfg.Server.Mode(cfg.AppEnv))
+	corsPolicy := middleware.NewCORSPolicy(corsOptions(cfg))
+	config.Subscribe(configWatcher, corsOptions, corsPolicy.Update)
+
+	rateLimiter := middleware.NewRateLimiter(rateLimitOptions(cfg))
+	config.Subscribe(configWatcher, rateLimitOptions, rateLimiter.Update)
+	lc.Append(lifecycle.Worker("rate limiter cleanup", rateLimiter.Run))
+
+	lc.Append(lifecycle.Worker("config watcher", configWatcher.Run))
+	slog.Info("Config watcher initialized")
+
+	router := gin.New()
+	router.Use(
+		middleware.Tracing(),
+		middleware.RequestID(),
+		middleware.RequestLogger(logger),
+		middleware.RequestMetrics(metricsRegistry),
+		middleware.ErrorHandler(i18nBundle, userRepo),
+		middleware.Recovery(),
+		corsPolicy.Handler(),
+		middleware.AuditContext(),
+	)
+	router.NoRoute(middleware.RouteNotFound())
 
 	// Setup routes
-	v1 := router.Group("/api/v1")
-	{
-		app_router.SetupUserRoutes(v1, userHandler)
+	err = application.Mount(app.Routes{
+		API:  router.Group("/api/v1", rateLimiter.Handler()),
+		Root: router,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Ping route for health check
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "pong"})
 	})
+	app_router.SetupHealthRoutes(router, handler.NewHealthHandler(healthRegistry))
 
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.AppPort),
-		Handler: router,
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
-	go func() {
-		log.Printf("Server listening on %d", cfg.AppPort)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("listen: %s\n", err)
+	if cfg.Admin.Addr != "" {
+		adminHandler := admin.NewHandler(admin.Options{
+			Metrics:  metricsRegistry.Handler(),
+			Token:    cfg.Secret("admin.token"),
+			Config:   func() map[string]any { return configWatcher.Current().Redacted() },
+			Routes:   func() []admin.Route { return admin.GinRoutes(router) },
+			LogLevel: logLevel,
+			Health:   healthRegistry,
+		})
+		adminSrv := &http.Server{
+			Addr:              cfg.Admin.Addr,
+			Handler:           adminHandler,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 		}
-	}()
-
-	// Wait for interrupt signal to gracefully shut down the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
+		// The admin server stops after the API server, so metrics can be scraped while requests drain
+		lc.Append(lifecycle.HTTPServer("Admin server", adminSrv, lc.Fail))
+	}
+	lc.Append(lifecycle.HTTPServer("API server", srv, lc.Fail))
+	// Stopped first: /readyz fails while the API server keeps serving for the drain period
+	lc.Append(lifecycle.Drain(cfg.Server.DrainPeriod, healthRegistry.ShutDown))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+	// Wait for interrupt signal to gracefully shut down the server; a second signal kills it
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := lc.Start(ctx); err != nil {
+		return err
 	}
+	if err := lc.Wait(ctx); err != nil {
+		return err
+	}
+	slog.Info("Shutting down server")
+	return nil
+}
 
-	log.Println("Server exiting")
+// defaultApplicationName prefixes the application_name of database sessions unless POSTGRES_URL sets one
+const defaultApplicationName = "api"
+
+// setApplicationName is named like the sqlc queries, so its spans are too
+const setApplicationName = `-- name: SetApplicationName :exec
+SELECT set_config('application_name', $1, false)`
+
+// applicationName combines the base name and the request ID within the 63 bytes Postgres keeps
+func applicationName(base, requestID string) string {
+	name := base
+	if requestID != "" {
+		name += " " + requestID
+	}
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
 }
 
-func initDB(databaseURL string) (*pgxpool.Pool, error) {
-	pgxpoolCfg, err := pgxpool.ParseConfig(databaseURL)
+// initDB creates the connection pool; new connections authenticate with the credentials of the current dbURL,
+// so a rotated database password applies without a restart.
+func initDB(dbCfg config.DBConfig, dbURL func() string) (*pgxpool.Pool, error) {
+	pgxpoolCfg, err := pgxpool.ParseConfig(dbCfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse database URL: %w", err)
 	}
 
-	// You can configure pool settings here, e.g.,
-	// pgxpool_cfg.MaxConns = 10
-	// pgxpool_cfg.MinConns = 2
-	// pgxpool_cfg.MaxConnLifetime = time.Hour
-	// pgxpool_cfg.MaxConnIdleTime = 30 * time.Minute
-	// pgxpool_cfg.HealthCheckPeriod = time.Minute
-	// pgxpool_cfg.ConnConfig.ConnectTimeout = 5 * time.Second
+	if dbCfg.MaxConns > 0 {
+		pgxpoolCfg.MaxConns = dbCfg.MaxConns
+	}
+	pgxpoolCfg.MinConns = dbCfg.MinConns
+	pgxpoolCfg.MaxConnLifetime = dbCfg.MaxConnLifetime
+	pgxpoolCfg.MaxConnIdleTime = dbCfg.MaxConnIdleTime
+	pgxpoolCfg.HealthCheckPeriod = dbCfg.HealthCheckPeriod
+	pgxpoolCfg.ConnConfig.ConnectTimeout = dbCfg.ConnectTimeout
+	pgxpoolCfg.ConnConfig.Tracer = tracing.NewPgxTracer()
+	pgxpoolCfg.BeforeConnect = func(ctx context.Context, connCfg *pgx.ConnConfig) error {
+		current, err := pgx.ParseConfig(dbURL())
+		if err != nil {
+			return fmt.Errorf("unable to parse database URL: %w", err)
+		}
+		connCfg.User = current.User
+		connCfg.Password = current.Password
+		return nil
+	}
+	// Tag each session with the request it serves, so pg_stat_activity shows which request issued a query
+	baseName := pgxpoolCfg.ConnConfig.RuntimeParams["application_name"]
+	if baseName == "" {
+		baseName = defaultApplicationName
+	}
+	pgxpoolCfg.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		name := applicationName(baseName, requestid.FromContext(ctx))
+		// application_name is reported by the server, so an unchanged name costs no round trip
+		if conn.PgConn().ParameterStatus("application_name") == name {
+			return true
+		}
+		// The tag only helps debugging, so a failure keeps the connection rather than churning the pool
+		if _, err := conn.Exec(ctx, setApplicationName, name); err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to set application_name", slog.Any("error", err))
+		}
+		return true
+	}
+	// Resetting the name on release keeps idle sessions from showing a finished request.
+	// It runs after the connection is handed back, off the path of the request.
+	pgxpoolCfg.AfterRelease = func(conn *pgx.Conn) bool {
+		if conn.PgConn().ParameterStatus("application_name") == baseName {
+			return true
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), dbCfg.ConnectTimeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, setApplicationName, baseName); err != nil {
+			// A session that cannot be reset would keep its stale tag, so it is closed instead
+			slog.Warn("Failed to reset application_name, closing the connection", slog.Any("error", err))
+			return false
+		}
+		return true
+	}
 
 	dbPool, err := pgxpool.NewWithConfig(context.Background(), pgxpoolCfg)
 	if err != nil {
@@ -131,16 +354,23 @@ func initDB(databaseURL string) (*pgxpool.Pool, error) {
 	return dbPool, nil
 }
 
-func initRedis(redisURL string) (*redis.Client, error) {
+func initRedis(redisCfg config.RedisConfig) (*redis.Client, error) {
 	var rdb *redis.Client
 
-	opt, err := redis.ParseURL(redisURL)
+	opt, err := redis.ParseURL(redisCfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse Redis URL: %w", err)
 	}
+	if redisCfg.PoolSize > 0 {
+		opt.PoolSize = redisCfg.PoolSize
+	}
+	opt.DialTimeout = redisCfg.DialTimeout
+	opt.ReadTimeout = redisCfg.ReadTimeout
+	opt.WriteTimeout = redisCfg.WriteTimeout
 	rdb = redis.NewClient(opt)
+	rdb.AddHook(tracing.NewRedisHook())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), redisCfg.DialTimeout)
 	defer cancel()
 
 	_, err = rdb.Ping(ctx).Result()
@@ -150,3 +380,76 @@ func initRedis(redisURL string) (*redis.Client, error) {
 	}
 	return rdb, nil
 }
+
+// setupLogger makes the logger configured by cfg the default; its level can be changed through the returned variable
+func setupLogger(cfg *config.Config) (*slog.Logger, *slog.LevelVar) {
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(parseLogLevel(cfg.Log.Level))
+	logger := logging.New(os.Stderr, logging.Options{
+		Level:      logLevel,
+		Format:     cfg.Log.FormatFor(cfg.AppEnv),
+		RedactKeys: cfg.Log.RedactKeys,
+	})
+	slog.SetDefault(logger)
+	return logger, logLevel
+}
+
+// parseLogLevel converts a level validated by config
+func parseLogLevel(level string) slog.Level {
+	var slogLevel slog.Level
+	_ = slogLevel.UnmarshalText([]byte(level))
+	return slogLevel
+}
+
+func corsOptions(cfg *config.Config) middleware.CORSOptions {
+	return middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+}
+
+func rateLimitOptions(cfg *config.Config) middleware.RateLimitOptions {
+	return middleware.RateLimitOptions{
+		Enabled:           cfg.RateLimit.Enabled,
+		RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+		Burst:             cfg.RateLimit.Burst,
+	}
+}
+
+func initBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.Blob.Store {
+	case "local":
+		return storage.NewLocalBlobStore(cfg.Blob.LocalDir, cfg.Blob.BaseURL, cfg.Secret("auth.secret_key"))
+	case "s3":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return storage.NewS3BlobStore(ctx, storage.S3Config{
+			Endpoint:  cfg.Blob.S3.Endpoint,
+			Region:    cfg.Blob.S3.Region,
+			Bucket:    cfg.Blob.S3.Bucket,
+			AccessKey: cfg.Secret("blob.s3.access_key"),
+			SecretKey: cfg.Secret("blob.s3.secret_key"),
+			UseSSL:    cfg.Blob.S3.UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q, expected \"local\" or \"s3\"", cfg.Blob.Store)
+	}
+}
+
+func initMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "log":
+		return mailer.NewLogMailer(), nil
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Secret("mail.smtp.password"),
+			From:     cfg.Mail.From,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q, expected \"log\" or \"smtp\"", cfg.Mail.Driver)
+	}
+}
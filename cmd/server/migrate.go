@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/db/migration"
+)
+
+const migrateSynopsis = `migrate <subcommand> [flags] [arguments]
+
+Subcommands:
+  up [N]       apply all pending migrations, or the next N
+  down N       roll back the last N migrations
+  down -all    roll back every migration
+  status       print the current and latest version and the pending migrations
+  force V      set the version without running migrations, after fixing a failed one by hand;
+               "force -- -1" marks no migration as applied
+
+With -wait, connecting is retried for that long, e.g. while the database container starts.
+Concurrent runs wait for each other on an advisory lock, so every replica may run "migrate up" on start.`
+
+// migrateCommand runs the embedded migrations in-process, so the image needs no migrate CLI
+func migrateCommand(args []string) error {
+	sub, args, err := subcommand(args, migrateSynopsis, "up", "down", "status", "force")
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("migrate "+sub, flag.ContinueOnError)
+	wait := flags.Duration("wait", 0, "keep retrying to connect to the database for this long")
+	all := false
+	if sub == "down" {
+		flags.BoolVar(&all, "all", false, "roll back every migration")
+	}
+	cfg, loader, err := loadConfig(flags, migrateSynopsis, args)
+	if err != nil {
+		return err
+	}
+	migrations, err := moduleMigrations(cfg, loader)
+	if err != nil {
+		return err
+	}
+
+	run, err := migrateAction(sub, flags.Args(), all, migrations)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	m, err := waitForMigrate(ctx, cfg, migrations, *wait)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	// Stops after the migration in progress; the next run continues from there
+	context.AfterFunc(ctx, func() { m.GracefulStop <- true })
+
+	err = run(m)
+	if errors.Is(err, migrate.ErrNoChange) {
+		slog.Info("No migrations to run")
+		return nil
+	}
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return fmt.Errorf("database is dirty at version %d: fix the failed migration by hand, then run '%s migrate force <version>' with the version it left the schema at", dirty.Version, programName)
+	}
+	return err
+}
+
+// migrateAction checks the arguments of a subcommand before anything connects to the database
+func migrateAction(sub string, args []string, all bool, migrations fs.FS) (func(m *migrate.Migrate) error, error) {
+	switch sub {
+	case "up":
+		switch len(args) {
+		case 0:
+			return withVersion((*migrate.Migrate).Up), nil
+		case 1:
+			n, err := positiveArg(args[0])
+			return withVersion(func(m *migrate.Migrate) error { return m.Steps(n) }), err
+		}
+		return nil, usageErrorf("up takes at most one argument, the number of migrations")
+	case "down":
+		switch {
+		case all && len(args) == 0:
+			return withVersion((*migrate.Migrate).Down), nil
+		case !all && len(args) == 1:
+			n, err := positiveArg(args[0])
+			return withVersion(func(m *migrate.Migrate) error { return m.Steps(-n) }), err
+		}
+		return nil, usageErrorf("down takes either the number of migrations or -all")
+	case "status":
+		if len(args) > 0 {
+			return nil, usageErrorf("status takes no arguments")
+		}
+		return func(m *migrate.Migrate) error { return migrateStatus(m, migrations) }, nil
+	default: // force
+		if len(args) != 1 {
+			return nil, usageErrorf("force takes the version to set, or -- -1 for none")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return nil, usageErrorf("version %q must be a migration number or -1", args[0])
+		}
+		return withVersion(func(m *migrate.Migrate) error { return m.Force(version) }), nil
+	}
+}
+
+// withVersion logs the version the database is at once fn succeeded
+func withVersion(fn func(m *migrate.Migrate) error) func(m *migrate.Migrate) error {
+	return func(m *migrate.Migrate) error {
+		if err := fn(m); err != nil {
+			return err
+		}
+		version, dirty, err := m.Version()
+		switch {
+		case errors.Is(err, migrate.ErrNilVersion):
+			slog.Info("No migrations applied")
+		case err != nil:
+			return err
+		default:
+			slog.Info("Database migrated", slog.Uint64("version", uint64(version)), slog.Bool("dirty", dirty))
+		}
+		return nil
+	}
+}
+
+// migrateStatus prints the version of the database and the migrations of fsys not applied yet
+func migrateStatus(m *migrate.Migrate, migrations fs.FS) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	latest := migration.LatestVersion(migrations)
+	fmt.Printf("version: %d\nlatest:  %d\n", version, latest)
+	if dirty {
+		fmt.Printf("dirty:   the migration to version %d failed; fix it by hand, then run '%s migrate force <version>'\n", version, programName)
+	}
+
+	pending, err := fs.Glob(migrations, "*.up.sql")
+	if err != nil {
+		return err
+	}
+	for _, name := range pending {
+		prefix, _, _ := strings.Cut(name, "_")
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(v) > version {
+			fmt.Println("pending:", strings.TrimSuffix(name, ".up.sql"))
+		}
+	}
+	return nil
+}
+
+// positiveArg parses a number of migrations
+func positiveArg(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, usageErrorf("number of migrations %q must be a positive integer", arg)
+	}
+	return n, nil
+}
+
+// waitForMigrate retries newMigrate once a second until it succeeds, wait elapsed or ctx is done
+func waitForMigrate(ctx context.Context, cfg *config.Config, migrations fs.FS, wait time.Duration) (*migrate.Migrate, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		m, err := newMigrate(cfg, migrations)
+		if err == nil || time.Now().After(deadline) {
+			return m, err
+		}
+		slog.Info("Waiting for the database", slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// newMigrate connects with the current credentials of db.url and reads the migrations of every module
+func newMigrate(cfg *config.Config, migrations fs.FS) (*migrate.Migrate, error) {
+	connCfg, err := pgx.ParseConfig(cfg.Secret("db.url")())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse database URL: %w", err)
+	}
+	connCfg.ConnectTimeout = cfg.DB.ConnectTimeout
+	db := stdlib.OpenDB(*connCfg)
+
+	driver, err := migratepgx.WithInstance(db, &migratepgx.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to connect to the database: %w", err)
+	}
+	source, err := iofs.New(migrations, ".")
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	m.Log = migrateLogger{}
+	return m, nil
+}
+
+// migrateLogger reports each migration as it runs
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
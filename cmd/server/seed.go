@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+const seedSynopsis = `seed [flags]
+
+Creates an admin, admin@example.com, and sample users, all with the same password.
+Users that exist are skipped, so seeding again only adds what is missing. Refuses to run in production.`
+
+// seedAdminEmail is the admin account of development databases
+const seedAdminEmail = "admin@example.com"
+
+var (
+	seedFirstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken"}
+	seedLastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson"}
+)
+
+// seedCommand fills a development database through the user service
+func seedCommand(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	count := flags.Int("users", 20, "number of sample users besides the admin")
+	password := flags.String("password", "changeme", "password of every seeded user")
+	cfg, loader, err := loadConfig(flags, seedSynopsis, args)
+	if err != nil {
+		return err
+	}
+	switch {
+	case flags.NArg() > 0:
+		return usageErrorf("seed takes no arguments")
+	case *count < 0:
+		return usageErrorf("-users must not be negative")
+	case len(*password) < minPasswordLength:
+		return usageErrorf("-password must be at least %d characters", minPasswordLength)
+	case cfg.AppEnv == config.ProductionEnv:
+		return errors.New("refusing to seed a production database")
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	application, closeApp, err := openApp(cfg, loader)
+	if err != nil {
+		return err
+	}
+	defer closeApp()
+	userService, err := app.Get[service.UserService](application)
+	if err != nil {
+		return err
+	}
+	userRepo, err := app.Get[repository.UserRepository](application)
+	if err != nil {
+		return err
+	}
+
+	users := make([]sqlc.CreateUserParams, 0, *count+1)
+	users = append(users, sqlc.CreateUserParams{FirstName: "Admin", LastName: "User", Email: seedAdminEmail, IsAdmin: true})
+	for i := range *count {
+		users = append(users, sqlc.CreateUserParams{
+			FirstName: seedFirstNames[i%len(seedFirstNames)],
+			LastName:  seedLastNames[(i/len(seedFirstNames))%len(seedLastNames)],
+			Email:     fmt.Sprintf("user%03d@example.com", i+1),
+		})
+	}
+	emails := make([]string, len(users))
+	for i, u := range users {
+		emails[i] = u.Email
+	}
+	existing, err := userRepo.ListExistingEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		skip[email] = true
+	}
+
+	created := 0
+	for _, params := range users {
+		if skip[params.Email] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		params.HashedPassword = *password
+		if _, err := userService.CreateUser(ctx, params); err != nil {
+			return fmt.Errorf("failed to create %s: %w", params.Email, err)
+		}
+		created++
+	}
+	slog.Info("Database seeded", slog.Int("created", created), slog.Int("skipped", len(existing)))
+	fmt.Printf("Created %d users, %d existed already. Sign in as %s with password %q.\n", created, len(existing), seedAdminEmail, *password)
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+const userSynopsis = `user <subcommand> [flags]
+
+Subcommands:
+  create -email E -first-name F -last-name L [-admin] [-password-stdin]
+  reset-password -email E [-password-stdin]
+
+Without -password-stdin a random password is generated and printed once.
+Changes are recorded in the audit log without an actor, like those of background jobs.`
+
+// minPasswordLength matches the validation of the API
+const minPasswordLength = 8
+
+// userCommand manages accounts through the user service, e.g. to create the first admin
+func userCommand(args []string) error {
+	sub, args, err := subcommand(args, userSynopsis, "create", "reset-password")
+	if err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("user "+sub, flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the user")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of standard input")
+	var firstName, lastName string
+	var admin bool
+	if sub == "create" {
+		flags.StringVar(&firstName, "first-name", "", "first name of the user")
+		flags.StringVar(&lastName, "last-name", "", "last name of the user")
+		flags.BoolVar(&admin, "admin", false, "grant the admin role")
+	}
+	cfg, loader, err := loadConfig(flags, userSynopsis, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if *email == "" {
+		return usageErrorf("-email is required")
+	}
+	if sub == "create" {
+		if err := validateNewUser(*email, firstName, lastName); err != nil {
+			return err
+		}
+	}
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext()
+	defer stop()
+	application, closeApp, err := openApp(cfg, loader)
+	if err != nil {
+		return err
+	}
+	defer closeApp()
+	userService, err := app.Get[service.UserService](application)
+	if err != nil {
+		return err
+	}
+
+	var user sqlc.User
+	var done string
+	switch sub {
+	case "create":
+		done = "Created"
+		// The role is part of the insert, so a failure cannot leave a user without it
+		user, err = userService.CreateUser(ctx, sqlc.CreateUserParams{
+			FirstName:      firstName,
+			LastName:       lastName,
+			Email:          *email,
+			HashedPassword: password,
+			IsAdmin:        admin,
+		})
+	case "reset-password":
+		done = "Reset the password of"
+		user, err = resetPassword(ctx, application, userService, *email, password)
+	}
+	if err != nil {
+		return err
+	}
+
+	role := "user"
+	if user.IsAdmin {
+		role = "admin"
+	}
+	fmt.Printf("%s %s (id %d, %s)\n", done, user.Email, user.ID, role)
+	if generated {
+		fmt.Println("Password:", password)
+	}
+	return nil
+}
+
+func resetPassword(ctx context.Context, a *app.App, userService service.UserService, email, password string) (sqlc.User, error) {
+	userRepo, err := app.Get[repository.UserRepository](a)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	user, err := userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	// The repository hashes the password
+	return userService.UpdateUser(ctx, sqlc.UpdateUserParams{
+		ID:             user.ID,
+		HashedPassword: pgtype.Text{String: password, Valid: true},
+	})
+}
+
+// validateNewUser applies the rules of the create user endpoint
+func validateNewUser(email, firstName, lastName string) error {
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return usageErrorf("-email %q is not a valid email address", email)
+	}
+	if firstName == "" || lastName == "" {
+		return usageErrorf("-first-name and -last-name are required")
+	}
+	return nil
+}
+
+// readPassword reads the password from stdin, or generates one when fromStdin is false
+func readPassword(fromStdin bool) (password string, generated bool, err error) {
+	if !fromStdin {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			return "", false, fmt.Errorf("failed to generate password: %w", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b), true, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", false, fmt.Errorf("failed to read the password: %w", err)
+	}
+	password = strings.TrimRight(line, "\r\n")
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return "", false, usageErrorf("the password must be at least %d characters", minPasswordLength)
+	}
+	return password, false, nil
+}
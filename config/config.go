@@ -1,43 +1,327 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"time"
+
+	"github.com/yourusername/yourprojectname/internal/secrets"
 )
 
-// Config holds all configuration for the application
+// Config holds all configuration for the application.
+// Every setting has a key in the config file (the dotted path of its `config` tags), an
+// environment variable (its `env` tag) and a command-line flag named like the key, see Load.
+// Settings tagged `secret` may reference an external secret instead of holding its value, see Secret.
+// Settings tagged `reload` take effect without a restart, see Watcher.
+// Settings tagged `secret` or `redact` are hidden by Redacted.
 type Config struct {
-	AppEnv    string
-	AppPort   int
-	DbURL     string
-	RedisURL  string
-	SecretKey string
-}
-
-// LoadConfig loads configuration from environment variables
-func LoadConfig() (*Config, error) {
-	return &Config{
-		AppEnv:    getEnv("APP_ENV", "development"),
-		AppPort:   getEnvAsInt("APP_PORT", 8080),
-		DbURL:     getEnv("POSTGRES_URL", "postgres://user:password@db:5432/mydatabase?sslmode=disable"),
-		RedisURL:  getEnv("REDIS_URL", "redis://redis:6379/0"),
-		SecretKey: getEnv("SECRET_KEY", "supersecret"),
-	}, nil
-}
-
-// Helper function to get an environment variable or return a default value
-func getEnv(key string, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+	AppEnv        string `config:"env" env:"APP_ENV"`
+	FrontendURL   string `config:"frontend_url" env:"FRONTEND_URL"`     // links in emails point here
+	AllowInsecure bool   `config:"allow_insecure" env:"ALLOW_INSECURE"` // boot in production despite failed safety checks, see CheckProduction
+
+	Server      ServerConfig      `config:"server"`
+	Admin       AdminConfig       `config:"admin"`
+	Health      HealthConfig      `config:"health"`
+	DB          DBConfig          `config:"db"`
+	Redis       RedisConfig       `config:"redis"`
+	Auth        AuthConfig        `config:"auth"`
+	Log         LogConfig         `config:"log"`
+	Tracing     TracingConfig     `config:"tracing"`
+	Blob        BlobConfig        `config:"blob"`
+	Mail        MailConfig        `config:"mail"`
+	EmailChange EmailChangeConfig `config:"email_change"`
+	Export      ExportConfig      `config:"export"`
+	Import      ImportConfig      `config:"import"`
+	Users       UsersConfig       `config:"users"`
+	Audit       AuditConfig       `config:"audit"`
+	Secrets     SecretsConfig     `config:"secrets"`
+	CORS        CORSConfig        `config:"cors"`
+	RateLimit   RateLimitConfig   `config:"rate_limit"`
+	Features    FeaturesConfig    `config:"features"`
+
+	secretStore *secrets.Store // current values of referenced secrets, nil before Load
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Port              int           `config:"port" env:"APP_PORT"`
+	GinMode           string        `config:"gin_mode" env:"GIN_MODE"` // debug, release or test; empty means release in production, debug otherwise
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `config:"read_timeout" env:"SERVER_READ_TIMEOUT"`   // 0 means no limit
+	WriteTimeout      time.Duration `config:"write_timeout" env:"SERVER_WRITE_TIMEOUT"` // 0 means no limit; exports stream for long
+	IdleTimeout       time.Duration `config:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	DrainPeriod       time.Duration `config:"drain_period" env:"SERVER_DRAIN_PERIOD"` // time between failing /readyz and closing the listener
+	ShutdownTimeout   time.Duration `config:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+// Mode returns the Gin mode to run in for the given environment.
+func (s ServerConfig) Mode(appEnv string) string {
+	switch {
+	case s.GinMode != "":
+		return s.GinMode
+	case appEnv == ProductionEnv:
+		return "release"
+	}
+	return "debug"
+}
+
+// AdminConfig configures the admin server, a separate listener for operational endpoints such as /metrics.
+type AdminConfig struct {
+	Addr  string `config:"addr" env:"ADMIN_ADDR"`                 // host:port, e.g. :9090; empty disables the admin server
+	Token string `config:"token" env:"ADMIN_TOKEN" secret:"true"` // bearer token for /debug; without it only local clients may use /debug
+}
+
+// HealthConfig configures the readiness and startup probes.
+type HealthConfig struct {
+	CheckTimeout time.Duration `config:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"` // per dependency check
+}
+
+// DBConfig configures the PostgreSQL connection pool.
+type DBConfig struct {
+	URL               string        `config:"url" env:"POSTGRES_URL" secret:"true"`
+	MaxConns          int32         `config:"max_conns" env:"DB_MAX_CONNS"` // 0 uses the pgx default
+	MinConns          int32         `config:"min_conns" env:"DB_MIN_CONNS"`
+	MaxConnLifetime   time.Duration `config:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime   time.Duration `config:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	HealthCheckPeriod time.Duration `config:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD"`
+	ConnectTimeout    time.Duration `config:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+}
+
+// RedisConfig configures the Redis client.
+type RedisConfig struct {
+	URL          string        `config:"url" env:"REDIS_URL"`
+	PoolSize     int           `config:"pool_size" env:"REDIS_POOL_SIZE"` // 0 uses the go-redis default
+	DialTimeout  time.Duration `config:"dial_timeout" env:"REDIS_DIAL_TIMEOUT"`
+	ReadTimeout  time.Duration `config:"read_timeout" env:"REDIS_READ_TIMEOUT"`
+	WriteTimeout time.Duration `config:"write_timeout" env:"REDIS_WRITE_TIMEOUT"`
+}
+
+// AuthConfig configures token authentication.
+type AuthConfig struct {
+	SecretKey string `config:"secret_key" env:"SECRET_KEY" secret:"true"` // signs JWTs and local blob URLs
+}
+
+// LogConfig configures logging.
+type LogConfig struct {
+	Level      string   `config:"level" env:"LOG_LEVEL" reload:"true"` // debug, info, warn or error
+	Format     string   `config:"format" env:"LOG_FORMAT"`             // json, text or pretty; empty means json in production, pretty otherwise
+	RedactKeys []string `config:"redact_keys" env:"LOG_REDACT_KEYS"`   // attributes whose key contains one of these are redacted
+}
+
+// FormatFor returns the log format to use in the given environment.
+func (l LogConfig) FormatFor(appEnv string) string {
+	switch {
+	case l.Format != "":
+		return l.Format
+	case appEnv == ProductionEnv:
+		return "json"
 	}
-	return defaultValue
+	return "pretty"
+}
+
+// TracingConfig configures OpenTelemetry tracing.
+type TracingConfig struct {
+	Exporter    string  `config:"exporter" env:"TRACING_EXPORTER"`         // otlp, stdout or none
+	Endpoint    string  `config:"endpoint" env:"TRACING_OTLP_ENDPOINT"`    // OTLP/HTTP collector, e.g. http://otel-collector:4318
+	ServiceName string  `config:"service_name" env:"TRACING_SERVICE_NAME"` // service.name resource attribute
+	SampleRatio float64 `config:"sample_ratio" env:"TRACING_SAMPLE_RATIO"` // share of new traces recorded; sampled parents are always followed
+}
+
+// BlobConfig configures blob storage (avatars, exports, ...).
+type BlobConfig struct {
+	Store    string        `config:"store" env:"BLOB_STORE"` // local or s3
+	LocalDir string        `config:"local_dir" env:"BLOB_LOCAL_DIR"`
+	BaseURL  string        `config:"base_url" env:"BLOB_BASE_URL"` // public URL of the blob download route, used to sign local URLs
+	URLTTL   time.Duration `config:"url_ttl" env:"BLOB_URL_TTL"`
+	S3       S3Config      `config:"s3"`
 }
 
-// Helper function to get an environment variable as int or return a default value
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.Atoi(valueStr); err == nil {
-		return value
+// S3Config configures an S3-compatible blob store.
+type S3Config struct {
+	Endpoint  string `config:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `config:"region" env:"S3_REGION"`
+	Bucket    string `config:"bucket" env:"S3_BUCKET"`
+	AccessKey string `config:"access_key" env:"S3_ACCESS_KEY" secret:"true"`
+	SecretKey string `config:"secret_key" env:"S3_SECRET_KEY" secret:"true"`
+	UseSSL    bool   `config:"use_ssl" env:"S3_USE_SSL"`
+}
+
+// MailConfig configures outgoing email.
+type MailConfig struct {
+	Driver string     `config:"driver" env:"MAIL_DRIVER"` // log or smtp
+	From   string     `config:"from" env:"MAIL_FROM"`
+	SMTP   SMTPConfig `config:"smtp"`
+}
+
+// SMTPConfig configures the SMTP mail driver.
+type SMTPConfig struct {
+	Host     string `config:"host" env:"SMTP_HOST"`
+	Port     int    `config:"port" env:"SMTP_PORT"`
+	Username string `config:"username" env:"SMTP_USERNAME"`
+	Password string `config:"password" env:"SMTP_PASSWORD" secret:"true"`
+}
+
+// EmailChangeConfig configures the email change flow.
+type EmailChangeConfig struct {
+	ConfirmTTL time.Duration `config:"confirm_ttl" env:"EMAIL_CHANGE_TTL"`
+	RevertTTL  time.Duration `config:"revert_ttl" env:"EMAIL_REVERT_TTL"`
+}
+
+// ExportConfig configures GDPR data exports.
+type ExportConfig struct {
+	Workers int           `config:"workers" env:"EXPORT_WORKERS"`
+	TTL     time.Duration `config:"ttl" env:"EXPORT_TTL"`
+}
+
+// ImportConfig configures bulk user imports.
+type ImportConfig struct {
+	MaxBytes    int64 `config:"max_bytes" env:"IMPORT_MAX_BYTES"`
+	BatchSize   int   `config:"batch_size" env:"IMPORT_BATCH_SIZE"`
+	HashWorkers int   `config:"hash_workers" env:"IMPORT_HASH_WORKERS"` // 0 uses one worker per CPU
+}
+
+// UsersConfig configures user lookups and avatars.
+type UsersConfig struct {
+	AvatarMaxBytes  int64         `config:"avatar_max_bytes" env:"AVATAR_MAX_BYTES"`
+	BatchGetMaxKeys int           `config:"batch_get_max_keys" env:"BATCH_GET_MAX_KEYS"`
+	BatchWindow     time.Duration `config:"batch_window" env:"USER_BATCH_WINDOW"` // 0 disables coalescing of concurrent lookups
+	BatchMaxSize    int           `config:"batch_max_size" env:"USER_BATCH_MAX_SIZE"`
+	BatchTimeout    time.Duration `config:"batch_timeout" env:"USER_BATCH_TIMEOUT"` // bounds the query of a coalesced batch
+}
+
+// AuditConfig configures the audit log.
+type AuditConfig struct {
+	Retention time.Duration `config:"retention" env:"AUDIT_RETENTION"` // 0 keeps events forever
+}
+
+// SecretsConfig configures external secret providers.
+type SecretsConfig struct {
+	VaultAddr       string        `config:"vault_addr" env:"VAULT_ADDR"`
+	VaultToken      string        `config:"vault_token" env:"VAULT_TOKEN" redact:"true"`
+	VaultNamespace  string        `config:"vault_namespace" env:"VAULT_NAMESPACE"`
+	RefreshInterval time.Duration `config:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL"` // 0 disables refreshing
+}
+
+// CORSConfig configures cross-origin requests from browsers.
+type CORSConfig struct {
+	AllowedOrigins   []string      `config:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" reload:"true"` // comma-separated in env and flags; empty disables CORS, "*" allows any origin
+	AllowCredentials bool          `config:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" reload:"true"`
+	MaxAge           time.Duration `config:"max_age" env:"CORS_MAX_AGE" reload:"true"` // how long browsers may cache a preflight response
+}
+
+// RateLimitConfig configures per-client request rate limiting of the API.
+type RateLimitConfig struct {
+	Enabled           bool `config:"enabled" env:"RATE_LIMIT_ENABLED" reload:"true"`
+	RequestsPerSecond int  `config:"requests_per_second" env:"RATE_LIMIT_RPS" reload:"true"`
+	Burst             int  `config:"burst" env:"RATE_LIMIT_BURST" reload:"true"`
+}
+
+// FeaturesConfig switches optional features on and off; the routes of a disabled feature answer 404.
+type FeaturesConfig struct {
+	Avatars    bool `config:"avatars" env:"FEATURE_AVATARS" reload:"true"`
+	UserImport bool `config:"user_import" env:"FEATURE_USER_IMPORT" reload:"true"`
+	DataExport bool `config:"data_export" env:"FEATURE_DATA_EXPORT" reload:"true"`
+}
+
+// ProductionEnv is the AppEnv that enables the production safety checks.
+const ProductionEnv = "production"
+
+// Defaults returns the configuration used for every setting no source overrides.
+func Defaults() Config {
+	return Config{
+		AppEnv:      "development",
+		FrontendURL: "http://localhost:3000",
+		Server: ServerConfig{
+			Port:              8080,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   5 * time.Second,
+		},
+		Admin: AdminConfig{
+			Addr: ":9090",
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		DB: DBConfig{
+			URL:               "postgres://user:password@db:5432/mydatabase?sslmode=disable",
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+			ConnectTimeout:    5 * time.Second,
+		},
+		Redis: RedisConfig{
+			URL:          "redis://redis:6379/0",
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+		},
+		Auth: AuthConfig{
+			SecretKey: "supersecret",
+		},
+		Log: LogConfig{
+			Level:      "info",
+			RedactKeys: []string{"password", "token", "secret", "authorization", "cookie"},
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "golang-app-scaffold",
+			SampleRatio: 1,
+		},
+		Blob: BlobConfig{
+			Store:    "local",
+			LocalDir: "./data/blobs",
+			BaseURL:  "http://localhost:8080/api/v1/blobs",
+			URLTTL:   15 * time.Minute,
+			S3: S3Config{
+				Endpoint: "minio:9000",
+				Region:   "us-east-1",
+				Bucket:   "uploads",
+			},
+		},
+		Mail: MailConfig{
+			Driver: "log",
+			From:   "no-reply@example.com",
+			SMTP: SMTPConfig{
+				Host: "localhost",
+				Port: 587,
+			},
+		},
+		EmailChange: EmailChangeConfig{
+			ConfirmTTL: 24 * time.Hour,
+			RevertTTL:  7 * 24 * time.Hour,
+		},
+		Export: ExportConfig{
+			Workers: 2,
+			TTL:     7 * 24 * time.Hour,
+		},
+		Import: ImportConfig{
+			MaxBytes:  100 << 20,
+			BatchSize: 1000,
+		},
+		Users: UsersConfig{
+			AvatarMaxBytes:  5 << 20,
+			BatchGetMaxKeys: 100,
+			BatchWindow:     2 * time.Millisecond,
+			BatchMaxSize:    100,
+			BatchTimeout:    5 * time.Second,
+		},
+		Audit: AuditConfig{
+			Retention: 365 * 24 * time.Hour,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: 5 * time.Minute,
+		},
+		CORS: CORSConfig{
+			MaxAge: 10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 10,
+			Burst:             20,
+		},
+		Features: FeaturesConfig{
+			Avatars:    true,
+			UserImport: true,
+			DataExport: true,
+		},
 	}
-	return defaultValue
 }
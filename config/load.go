@@ -0,0 +1,379 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/yourusername/yourprojectname/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the config file when the -config flag is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// FileEnvSuffix marks the variant of an environment variable naming a file to read the value from,
+// e.g. SECRET_KEY_FILE=/run/secrets/secret_key.
+const FileEnvSuffix = "_FILE"
+
+// Loader loads the configuration from layered sources. In increasing precedence:
+// Defaults, the YAML or TOML config file, environment variables (or the files named by their
+// *_FILE variants) and command-line flags.
+type Loader struct {
+	configFile string
+	flags      map[string]string // values of the flags that were set, by key
+}
+
+// NewLoader registers -config and one flag per setting on fs, e.g. -server.port for APP_PORT.
+// Call Load after fs.Parse.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{flags: make(map[string]string)}
+	fs.StringVar(&l.configFile, "config", "", "YAML or TOML config file (default $"+ConfigFileEnv+")")
+	defaults := Defaults()
+	for _, s := range settings(&defaults) {
+		fs.Var(flagValue{key: s.key, flags: l.flags, isBool: s.value.Kind() == reflect.Bool}, s.key, "overrides $"+s.env)
+	}
+	return l
+}
+
+// LoadConfig loads and validates the configuration, with flags parsed from args.
+func LoadConfig(args []string) (*Config, error) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	l := NewLoader(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return l.Load()
+}
+
+// Load merges all sources and validates the result.
+// Every unknown key, unparsable value and invalid setting is reported in one Problems error.
+func (l *Loader) Load() (*Config, error) {
+	cfg := Defaults()
+	index := make(map[string]setting)
+	for _, s := range settings(&cfg) {
+		index[s.key] = s
+	}
+	var problems Problems
+
+	if path := l.path(); path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s, ok := index[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key %s", path, key))
+				continue
+			}
+			if err := setValue(s.value, values[key]); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: %v", path, key, err))
+			}
+		}
+	}
+
+	fileProvider := secrets.NewFileSecretProvider()
+	for _, s := range settings(&cfg) {
+		// An empty variable counts as unset, as compose files often pass them through blank
+		value, file := os.Getenv(s.env), os.Getenv(s.env+FileEnvSuffix)
+		switch {
+		case value != "" && file != "":
+			problems = append(problems, fmt.Sprintf("$%s and $%s%s are both set, use only one", s.env, s.env, FileEnvSuffix))
+			value = ""
+		case file != "" && s.secret:
+			// Kept as a reference, so the file is read again when secrets are refreshed
+			value = "file:" + file
+		case file != "":
+			var err error
+			if value, err = fileProvider.Fetch(context.Background(), file); err != nil {
+				problems = append(problems, fmt.Sprintf("$%s%s: %v", s.env, FileEnvSuffix, err))
+			}
+		}
+		if value != "" {
+			if err := setString(s.value, value); err != nil {
+				problems = append(problems, fmt.Sprintf("$%s: %v", s.env, err))
+			}
+		}
+		if v, ok := l.flags[s.key]; ok {
+			if err := setString(s.value, v); err != nil {
+				problems = append(problems, fmt.Sprintf("-%s: %v", s.key, err))
+			}
+		}
+	}
+
+	problems = append(problems, cfg.resolveSecrets(context.Background())...)
+
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err.(Problems)...)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return &cfg, nil
+}
+
+// path returns the config file to load, "" for none
+func (l *Loader) path() string {
+	if l.configFile != "" {
+		return l.configFile
+	}
+	return os.Getenv(ConfigFileEnv)
+}
+
+// Problems lists everything wrong with a configuration.
+type Problems []string
+
+func (p Problems) Error() string {
+	return fmt.Sprintf("%d configuration problem(s):\n  - %s", len(p), strings.Join(p, "\n  - "))
+}
+
+// setting is one leaf of Config
+type setting struct {
+	key        string // dotted path of config tags, e.g. "server.port"
+	env        string
+	secret     bool
+	redact     bool // hidden by Redacted; implied by secret
+	reloadable bool
+	value      reflect.Value // addressable field
+}
+
+// settings lists the leaves of cfg in declaration order
+func settings(cfg *Config) []setting {
+	var out []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := prefix + field.Tag.Get("config")
+			if field.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			out = append(out, setting{
+				key:        key,
+				env:        field.Tag.Get("env"),
+				secret:     field.Tag.Get("secret") == "true",
+				redact:     field.Tag.Get("secret") == "true" || field.Tag.Get("redact") == "true",
+				reloadable: field.Tag.Get("reload") == "true",
+				value:      v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	stringSliceType = reflect.TypeOf([]string(nil))
+)
+
+// setString parses s into v according to v's type
+func setString(v reflect.Value, s string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected e.g. 30s or 15m", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Type() == stringSliceType:
+		// Comma-separated, as environment variables and flags hold a single string
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		v.SetBool(b)
+	case v.CanInt():
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case v.CanFloat():
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// setValue assigns a value decoded from a config file, converting it like setString
+func setValue(v reflect.Value, raw any) error {
+	switch raw := raw.(type) {
+	case string:
+		return setString(v, raw)
+	case bool:
+		if v.Kind() != reflect.Bool {
+			return fmt.Errorf("expected %s, got a boolean", typeName(v))
+		}
+		v.SetBool(raw)
+	case []any:
+		if v.Type() != stringSliceType {
+			return fmt.Errorf("expected %s, got a list", typeName(v))
+		}
+		items := make([]string, 0, len(raw))
+		for _, item := range raw {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected %s", typeName(v))
+			}
+			items = append(items, s)
+		}
+		v.Set(reflect.ValueOf(items))
+	case int, int64, uint64, float64:
+		if v.CanFloat() {
+			f, _ := toFloat64(raw)
+			v.SetFloat(f)
+			return nil
+		}
+		if v.Type() == durationType || !v.CanInt() {
+			return fmt.Errorf("expected %s, got a number", typeName(v))
+		}
+		n, ok := toInt64(raw)
+		if !ok || v.OverflowInt(n) {
+			return fmt.Errorf("%v is not a valid %s", raw, typeName(v))
+		}
+		v.SetInt(n)
+	default:
+		return fmt.Errorf("expected %s", typeName(v))
+	}
+	return nil
+}
+
+func toInt64(raw any) (int64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n) && math.Abs(n) < math.MaxInt64
+	}
+	return 0, false
+}
+
+// toFloat64 converts a number decoded from a config file to float64
+func toFloat64(raw any) (float64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// typeName describes the expected value of v in config file terms
+func typeName(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return `a duration string such as "15m"`
+	case v.Kind() == reflect.String:
+		return "a string"
+	case v.Kind() == reflect.Bool:
+		return "a boolean"
+	case v.Type() == stringSliceType:
+		return "a list of strings"
+	case v.CanFloat():
+		return "a number"
+	}
+	return "an integer"
+}
+
+// readFile parses a YAML or TOML file, chosen by extension, into dotted keys
+func readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	values := make(map[string]any)
+	flatten(tree, "", values)
+	return values, nil
+}
+
+// flatten turns nested sections into dotted keys
+func flatten(tree map[string]any, prefix string, out map[string]any) {
+	for k, v := range tree {
+		if section, ok := v.(map[string]any); ok {
+			flatten(section, prefix+k+".", out)
+			continue
+		}
+		out[prefix+k] = v
+	}
+}
+
+// flagValue records a flag for Load, which parses it along with the other sources
+type flagValue struct {
+	key    string
+	flags  map[string]string
+	isBool bool // lets boolean settings be given without a value, e.g. -allow_insecure
+}
+
+func (f flagValue) String() string {
+	if f.flags == nil {
+		return ""
+	}
+	return f.flags[f.key]
+}
+
+func (f flagValue) Set(s string) error {
+	f.flags[f.key] = s
+	return nil
+}
+
+// IsBoolFlag implements the interface the flag package checks for flags that need no value
+func (f flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
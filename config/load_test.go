@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestLoader returns a loader with args parsed; a non-empty file is written as the YAML config file
+func newTestLoader(t *testing.T, file string, args ...string) *Loader {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	l := NewLoader(fs)
+	if file != "" {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		args = append([]string{"-config", path}, args...)
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse(%v): %v", args, err)
+	}
+	return l
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		args     []string
+		wantPort int
+		wantLog  string
+	}{
+		{name: "defaults", wantPort: 8080, wantLog: "info"},
+		{name: "file over defaults", file: "server:\n  port: 8081\nlog:\n  level: debug\n", wantPort: 8081, wantLog: "debug"},
+		{
+			name:     "environment over file",
+			file:     "server:\n  port: 8081\nlog:\n  level: debug\n",
+			env:      map[string]string{"APP_PORT": "8082"},
+			wantPort: 8082, wantLog: "debug",
+		},
+		{
+			name:     "flag over environment",
+			file:     "server:\n  port: 8081\n",
+			env:      map[string]string{"APP_PORT": "8082", "LOG_LEVEL": "warn"},
+			args:     []string{"-server.port", "8083"},
+			wantPort: 8083, wantLog: "warn",
+		},
+		{
+			name:     "empty variable counts as unset",
+			file:     "server:\n  port: 8081\n",
+			env:      map[string]string{"APP_PORT": ""},
+			wantPort: 8081, wantLog: "info",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := newTestLoader(t, tt.file, tt.args...).Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Port != tt.wantPort || cfg.Log.Level != tt.wantLog {
+				t.Errorf("port %d, log level %q; want %d, %q", cfg.Server.Port, cfg.Log.Level, tt.wantPort, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestLoad_FileVariantOfVariable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "port")
+	if err := os.WriteFile(path, []byte("9000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT"+FileEnvSuffix, path)
+
+	cfg, err := newTestLoader(t, "").Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9000 {
+		t.Errorf("port = %d, want the 9000 of $APP_PORT_FILE", cfg.Server.Port)
+	}
+}
+
+func TestLoad_BoolFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: false},
+		{args: []string{"-allow_insecure"}, want: true},
+		{args: []string{"-allow_insecure=true"}, want: true},
+		{args: []string{"-allow_insecure=false"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			cfg, err := newTestLoader(t, "", tt.args...).Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.AllowInsecure != tt.want {
+				t.Errorf("AllowInsecure = %v, want %v", cfg.AllowInsecure, tt.want)
+			}
+		})
+	}
+
+	// A flag that is not boolean still needs its value
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder))
+	NewLoader(fs)
+	if err := fs.Parse([]string{"-server.port"}); err == nil {
+		t.Error("Parse accepted -server.port without a value")
+	}
+}
+
+func TestLoad_ReportsAllProblems(t *testing.T) {
+	t.Setenv("APP_PORT", "eighty")
+	t.Setenv("SECRET_KEY", "inline")
+	t.Setenv("SECRET_KEY"+FileEnvSuffix, "/run/secrets/secret_key")
+	l := newTestLoader(t, "server:\n  prot: 8081\nlog:\n  level: loud\n", "-health.check_timeout", "soon")
+
+	_, err := l.Load()
+	var problems Problems
+	if !errors.As(err, &problems) {
+		t.Fatalf("Load = %v, want Problems", err)
+	}
+	for _, want := range []string{
+		"unknown key server.prot",
+		"$APP_PORT: ",
+		"$SECRET_KEY and $SECRET_KEY_FILE are both set",
+		"-health.check_timeout: ",
+		"log.level ($LOG_LEVEL): ",
+	} {
+		found := false
+		for _, p := range problems {
+			found = found || strings.Contains(p, want)
+		}
+		if !found {
+			t.Errorf("problems %q do not report %q", problems, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"net/url"
+	"slices"
+)
+
+// MinSecretKeyLength is the shortest auth.secret_key accepted in production, 256 bits for HS256.
+const MinSecretKeyLength = 32
+
+// knownSecrets are placeholder secrets from the defaults, examples and compose files
+var knownSecrets = []string{"supersecret", "yourverysecretkey", "secret", "changeme", "password", "postgres", "minioadmin"}
+
+// CheckProduction reports every setting that is unsafe to run in production with, in one Problems error.
+// It returns nil outside production. The caller decides whether AllowInsecure downgrades the result to a warning.
+func (c *Config) CheckProduction() error {
+	if c.AppEnv != ProductionEnv {
+		return nil
+	}
+	v := validator{envs: make(map[string]string)}
+	for _, s := range settings(c) {
+		v.envs[s.key] = s.env
+	}
+
+	v.check(!slices.Contains(knownSecrets, c.Auth.SecretKey), "auth.secret_key", "must not be a default or example value")
+	v.check(len(c.Auth.SecretKey) >= MinSecretKeyLength, "auth.secret_key", "must be at least %d bytes in production, got %d", MinSecretKeyLength, len(c.Auth.SecretKey))
+
+	if c.Admin.Token != "" {
+		v.check(!slices.Contains(knownSecrets, c.Admin.Token), "admin.token", "must not be a default or example value")
+	}
+
+	if u, err := url.Parse(c.DB.URL); err == nil {
+		password, _ := u.User.Password()
+		v.check(c.DB.URL != Defaults().DB.URL && !slices.Contains(knownSecrets, password), "db.url", "must not use a default or example password")
+		sslMode := u.Query().Get("sslmode")
+		v.check(sslMode != "disable" && sslMode != "allow", "db.url", "must not set sslmode=%s in production, use require or verify-full", sslMode)
+	}
+
+	if c.Blob.Store == "s3" {
+		v.check(!slices.Contains(knownSecrets, c.Blob.S3.SecretKey), "blob.s3.secret_key", "must not be a default or example value")
+	}
+
+	v.check(c.Server.Mode(c.AppEnv) != "debug", "server.gin_mode", "must not be debug in production")
+
+	if len(v.problems) > 0 {
+		return v.problems
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces the values of secret settings in Redacted
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns the settings as nested maps keyed like the config file, with secrets replaced by
+// RedactedValue and passwords in other URLs masked, for showing the effective configuration to operators.
+func (c *Config) Redacted() map[string]any {
+	out := make(map[string]any)
+	for _, s := range settings(c) {
+		var value any = s.value.Interface()
+		switch v := value.(type) {
+		case string:
+			if s.redact && v != "" {
+				value = RedactedValue
+			} else if u, err := url.Parse(v); err == nil && u.User != nil {
+				// e.g. a Redis URL with a password
+				if _, ok := u.User.Password(); ok {
+					value = u.Redacted()
+				}
+			}
+		case time.Duration:
+			value = v.String()
+		}
+
+		section := out
+		path := strings.Split(s.key, ".")
+		for _, name := range path[:len(path)-1] {
+			next, ok := section[name].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				section[name] = next
+			}
+			section = next
+		}
+		section[path[len(path)-1]] = value
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yourusername/yourprojectname/internal/secrets"
+	"github.com/yourusername/yourprojectname/internal/tracing"
+)
+
+// resolveSecrets replaces every secret setting holding a reference such as "vault:secret/data/app#secret_key"
+// or "file:/run/secrets/secret_key" with the referenced value, and remembers the reference for refreshing.
+func (c *Config) resolveSecrets(ctx context.Context) Problems {
+	c.secretStore = secrets.NewStore(map[string]secrets.SecretProvider{
+		"env":  secrets.NewEnvSecretProvider(),
+		"file": secrets.NewFileSecretProvider(),
+		"vault": secrets.NewVaultSecretProvider(secrets.VaultConfig{
+			Addr:      c.Secrets.VaultAddr,
+			Token:     c.Secrets.VaultToken,
+			Namespace: c.Secrets.VaultNamespace,
+			Client:    &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+		}),
+	})
+
+	var problems Problems
+	for _, s := range settings(c) {
+		if !s.secret || !c.secretStore.IsReference(s.value.String()) {
+			continue
+		}
+		value, err := c.secretStore.Add(ctx, s.key, s.value.String())
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s ($%s): %v", s.key, s.env, err))
+			continue
+		}
+		s.value.SetString(value)
+	}
+	return problems
+}
+
+// Secret returns a function reading the current value of the secret setting key, e.g. "auth.secret_key".
+// Referenced secrets follow refreshes by WatchSecrets; the struct fields keep the value they were loaded with.
+func (c *Config) Secret(key string) func() string {
+	if c.secretStore != nil && c.secretStore.Has(key) {
+		return func() string { return c.secretStore.Get(key) }
+	}
+	for _, s := range settings(c) {
+		if s.key == key && s.secret {
+			value := s.value.String()
+			return func() string { return value }
+		}
+	}
+	panic("config: no secret setting " + key)
+}
+
+// WatchSecrets fetches referenced secrets again every Secrets.RefreshInterval until ctx is cancelled.
+// onRefresh is called after each refresh with the keys whose value changed and the fetch errors, if any.
+func (c *Config) WatchSecrets(ctx context.Context, onRefresh func(changed []string, err error)) {
+	if c.secretStore == nil || c.Secrets.RefreshInterval <= 0 {
+		return
+	}
+	c.secretStore.Run(ctx, c.Secrets.RefreshInterval, onRefresh)
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate checks every setting and reports all invalid ones in one Problems error.
+func (c *Config) Validate() error {
+	v := validator{envs: make(map[string]string)}
+	for _, s := range settings(c) {
+		v.envs[s.key] = s.env
+	}
+
+	v.check(c.AppEnv != "", "env", "must not be empty")
+	v.url("frontend_url", c.FrontendURL, "http", "https")
+
+	v.check(c.Server.Port >= 1 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	if c.Server.GinMode != "" {
+		v.oneOf("server.gin_mode", c.Server.GinMode, "debug", "release", "test")
+	}
+	v.nonNegative("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	v.nonNegative("server.read_timeout", c.Server.ReadTimeout)
+	v.nonNegative("server.write_timeout", c.Server.WriteTimeout)
+	v.nonNegative("server.idle_timeout", c.Server.IdleTimeout)
+	v.nonNegative("server.drain_period", c.Server.DrainPeriod)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	if c.Admin.Addr != "" {
+		_, port, err := net.SplitHostPort(c.Admin.Addr)
+		v.check(err == nil && port != "", "admin.addr", "must be host:port or :port, got %q", c.Admin.Addr)
+		v.check(port != strconv.Itoa(c.Server.Port), "admin.addr", "must not use the port of the API server")
+	}
+	v.positive("health.check_timeout", c.Health.CheckTimeout)
+
+	v.url("db.url", c.DB.URL, "postgres", "postgresql")
+	v.check(c.DB.MaxConns >= 0, "db.max_conns", "must not be negative")
+	v.check(c.DB.MinConns >= 0, "db.min_conns", "must not be negative")
+	v.check(c.DB.MaxConns == 0 || c.DB.MinConns <= c.DB.MaxConns, "db.min_conns", "must not exceed db.max_conns (%d)", c.DB.MaxConns)
+	v.positive("db.max_conn_lifetime", c.DB.MaxConnLifetime)
+	v.positive("db.max_conn_idle_time", c.DB.MaxConnIdleTime)
+	v.positive("db.health_check_period", c.DB.HealthCheckPeriod)
+	v.positive("db.connect_timeout", c.DB.ConnectTimeout)
+
+	v.url("redis.url", c.Redis.URL, "redis", "rediss", "unix")
+	v.check(c.Redis.PoolSize >= 0, "redis.pool_size", "must not be negative")
+	v.positive("redis.dial_timeout", c.Redis.DialTimeout)
+	v.positive("redis.read_timeout", c.Redis.ReadTimeout)
+	v.positive("redis.write_timeout", c.Redis.WriteTimeout)
+
+	v.check(c.Auth.SecretKey != "", "auth.secret_key", "must not be empty")
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	if c.Log.Format != "" {
+		v.oneOf("log.format", c.Log.Format, "json", "text", "pretty")
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "otlp", "stdout", "none")
+	if c.Tracing.Exporter == "otlp" {
+		v.url("tracing.endpoint", c.Tracing.Endpoint, "http", "https")
+	}
+	v.check(c.Tracing.ServiceName != "", "tracing.service_name", "must not be empty")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	v.oneOf("blob.store", c.Blob.Store, "local", "s3")
+	v.positive("blob.url_ttl", c.Blob.URLTTL)
+	switch c.Blob.Store {
+	case "local":
+		v.check(c.Blob.LocalDir != "", "blob.local_dir", "must not be empty when blob.store is local")
+		v.url("blob.base_url", c.Blob.BaseURL, "http", "https")
+	case "s3":
+		v.check(c.Blob.S3.Endpoint != "", "blob.s3.endpoint", "must not be empty when blob.store is s3")
+		v.check(c.Blob.S3.Bucket != "", "blob.s3.bucket", "must not be empty when blob.store is s3")
+	}
+
+	v.oneOf("mail.driver", c.Mail.Driver, "log", "smtp")
+	v.check(strings.Contains(c.Mail.From, "@"), "mail.from", "must be an email address, got %q", c.Mail.From)
+	if c.Mail.Driver == "smtp" {
+		v.check(c.Mail.SMTP.Host != "", "mail.smtp.host", "must not be empty when mail.driver is smtp")
+		v.check(c.Mail.SMTP.Port >= 1 && c.Mail.SMTP.Port <= 65535, "mail.smtp.port", "must be between 1 and 65535, got %d", c.Mail.SMTP.Port)
+	}
+
+	v.positive("email_change.confirm_ttl", c.EmailChange.ConfirmTTL)
+	v.positive("email_change.revert_ttl", c.EmailChange.RevertTTL)
+
+	v.check(c.Export.Workers >= 1, "export.workers", "must be at least 1, got %d", c.Export.Workers)
+	v.positive("export.ttl", c.Export.TTL)
+
+	v.check(c.Import.MaxBytes >= 1, "import.max_bytes", "must be at least 1, got %d", c.Import.MaxBytes)
+	v.check(c.Import.BatchSize >= 1, "import.batch_size", "must be at least 1, got %d", c.Import.BatchSize)
+	v.check(c.Import.HashWorkers >= 0, "import.hash_workers", "must not be negative")
+
+	v.check(c.Users.AvatarMaxBytes >= 1, "users.avatar_max_bytes", "must be at least 1, got %d", c.Users.AvatarMaxBytes)
+	v.check(c.Users.BatchGetMaxKeys >= 1, "users.batch_get_max_keys", "must be at least 1, got %d", c.Users.BatchGetMaxKeys)
+	v.nonNegative("users.batch_window", c.Users.BatchWindow)
+	v.check(c.Users.BatchMaxSize >= 1, "users.batch_max_size", "must be at least 1, got %d", c.Users.BatchMaxSize)
+	v.positive("users.batch_timeout", c.Users.BatchTimeout)
+
+	v.nonNegative("audit.retention", c.Audit.Retention)
+
+	if c.Secrets.VaultAddr != "" {
+		v.url("secrets.vault_addr", c.Secrets.VaultAddr, "http", "https")
+	}
+	v.nonNegative("secrets.refresh_interval", c.Secrets.RefreshInterval)
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		u, err := url.Parse(origin)
+		v.check(origin == "*" || err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "",
+			"cors.allowed_origins", "%q is not an origin such as https://app.example.com, or *", origin)
+	}
+	v.check(!c.CORS.AllowCredentials || !slices.Contains(c.CORS.AllowedOrigins, "*"), "cors.allow_credentials", "must not be combined with the * origin")
+	v.nonNegative("cors.max_age", c.CORS.MaxAge)
+
+	if c.RateLimit.Enabled {
+		v.check(c.RateLimit.RequestsPerSecond >= 1, "rate_limit.requests_per_second", "must be at least 1, got %d", c.RateLimit.RequestsPerSecond)
+		v.check(c.RateLimit.Burst >= 1, "rate_limit.burst", "must be at least 1, got %d", c.RateLimit.Burst)
+	}
+
+	if len(v.problems) > 0 {
+		return v.problems
+	}
+	return nil
+}
+
+// validator collects problems, naming each setting by key and environment variable
+type validator struct {
+	envs     map[string]string
+	problems Problems
+}
+
+func (v *validator) check(ok bool, key, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf("%s ($%s): %s", key, v.envs[key], fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	v.check(d > 0, key, "must be a positive duration, got %s", d)
+}
+
+func (v *validator) nonNegative(key string, d time.Duration) {
+	v.check(d >= 0, key, "must not be negative, got %s", d)
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// url checks that value is an absolute URL with one of the given schemes.
+// The value is not echoed, as connection URLs may contain passwords.
+func (v *validator) url(key, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	switch {
+	case value == "":
+		v.check(false, key, "must not be empty")
+	case err != nil:
+		v.check(false, key, "is not a valid URL")
+	default:
+		v.check(slices.Contains(schemes, u.Scheme), key, "must be a %s URL", strings.Join(schemes, " or "))
+	}
+}
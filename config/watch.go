@@ -0,0 +1,191 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the burst of file events editors and Kubernetes produce for a single change
+const reloadDebounce = 250 * time.Millisecond
+
+// Watcher reloads the configuration on SIGHUP and whenever the config file changes.
+// A reload loads and validates every source again; an invalid result is rejected as a whole and the
+// running configuration stays in effect. Only settings tagged `reload` change: every other changed
+// setting keeps its running value and is logged as needing a restart.
+type Watcher struct {
+	loader  *Loader
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex // serializes reloads and subscriptions
+	subscribers []func(old, next *Config)
+}
+
+// NewWatcher creates a watcher reloading through loader, starting from cfg as returned by loader.Load.
+func NewWatcher(loader *Loader, cfg *Config) *Watcher {
+	w := &Watcher{loader: loader}
+	w.current.Store(cfg)
+	return w
+}
+
+// Current returns the configuration in effect. The returned value must not be modified.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe calls fn with the part of the configuration selected by get whenever a reload changes it.
+// fn runs on the reloading goroutine, after the new configuration is in effect.
+func Subscribe[T any](w *Watcher, get func(*Config) T, fn func(T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, func(old, next *Config) {
+		if value := get(next); !reflect.DeepEqual(get(old), value) {
+			fn(value)
+		}
+	})
+}
+
+// Reload loads the configuration again and, if it is valid, puts it into effect and notifies the subscribers.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := w.loader.Load()
+	if err != nil {
+		return err
+	}
+	old := w.current.Load()
+
+	oldSettings := settings(old)
+	var changed []string
+	for i, s := range settings(next) {
+		before := oldSettings[i].value
+		if reflect.DeepEqual(before.Interface(), s.value.Interface()) {
+			continue
+		}
+		switch {
+		case s.reloadable:
+			changed = append(changed, s.key)
+		case s.secret:
+			// Referenced secrets are refreshed by WatchSecrets, literal ones need a restart like any other setting
+			s.value.Set(before)
+		default:
+			slog.Warn("Setting changed but takes effect only after a restart, keeping the running value", slog.String("key", s.key), slog.String("env", s.env))
+			s.value.Set(before)
+		}
+	}
+	next.secretStore = old.secretStore
+
+	w.current.Store(next)
+	if len(changed) == 0 {
+		slog.Info("Configuration reloaded, no reloadable setting changed")
+		return nil
+	}
+	slog.Info("Configuration reloaded", slog.String("changed", strings.Join(changed, ", ")))
+	for _, notify := range w.subscribers {
+		notify(old, next)
+	}
+	return nil
+}
+
+// Run reloads on SIGHUP and on changes to the config file until ctx is cancelled.
+// Failed reloads are logged and leave the running configuration in effect.
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileChanges <-chan fsnotify.Event
+	if path := w.loader.path(); path != "" {
+		fsWatcher, err := watchFile(path)
+		if err != nil {
+			slog.Warn("Failed to watch the config file, reloading on SIGHUP only", slog.Any("error", err))
+		} else {
+			defer fsWatcher.Close()
+			fileChanges = filterEvents(ctx, fsWatcher, path)
+		}
+	}
+
+	debounce := time.NewTimer(reloadDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	reload := func(reason string) {
+		if err := w.Reload(); err != nil {
+			slog.Error("Rejected configuration reload, keeping the running configuration", slog.String("reason", reason), slog.Any("error", err))
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("SIGHUP")
+		case <-fileChanges:
+			debounce.Reset(reloadDebounce)
+		case <-debounce.C:
+			reload("config file changed")
+		}
+	}
+}
+
+// watchFile watches the directory of path, as editors and Kubernetes ConfigMap updates replace the file
+// instead of writing to it, which ends a watch on the file itself.
+func watchFile(path string) (*fsnotify.Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsWatcher.Add(filepath.Dir(path)); err != nil {
+		fsWatcher.Close()
+		return nil, fmt.Errorf("%s: %w", filepath.Dir(path), err)
+	}
+	return fsWatcher, nil
+}
+
+// filterEvents forwards the events concerning path, including the "..data" symlink swap of ConfigMap mounts
+func filterEvents(ctx context.Context, fsWatcher *fsnotify.Watcher, path string) <-chan fsnotify.Event {
+	out := make(chan fsnotify.Event)
+	name := filepath.Clean(path)
+	dataLink := filepath.Join(filepath.Dir(name), "..data")
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if filepath.Clean(event.Name) != name && filepath.Clean(event.Name) != dataLink {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Config file watch error", slog.Any("error", err))
+			}
+		}
+	}()
+	return out
+}
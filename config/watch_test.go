@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestWatcher returns a watcher over the environment, loaded once like at startup
+func newTestWatcher(t *testing.T) *Watcher {
+	t.Helper()
+	l := newTestLoader(t, "")
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return NewWatcher(l, cfg)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantErr    bool
+		wantLevel  string
+		wantPort   int
+		wantNotify bool
+	}{
+		{name: "nothing changed", wantLevel: "info", wantPort: 8080},
+		{name: "reloadable setting", env: map[string]string{"LOG_LEVEL": "debug"}, wantLevel: "debug", wantPort: 8080, wantNotify: true},
+		{name: "setting needing a restart", env: map[string]string{"APP_PORT": "8081"}, wantLevel: "info", wantPort: 8080},
+		{
+			name:       "both kinds",
+			env:        map[string]string{"LOG_LEVEL": "warn", "APP_PORT": "8081"},
+			wantLevel:  "warn",
+			wantPort:   8080,
+			wantNotify: true,
+		},
+		{name: "invalid configuration", env: map[string]string{"LOG_LEVEL": "loud"}, wantErr: true, wantLevel: "info", wantPort: 8080},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWatcher(t)
+			var notified []string
+			Subscribe(w, func(c *Config) string { return c.Log.Level }, func(level string) {
+				notified = append(notified, level)
+			})
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			err := w.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload = %v, want error %v", err, tt.wantErr)
+			}
+			cfg := w.Current()
+			if cfg.Log.Level != tt.wantLevel || cfg.Server.Port != tt.wantPort {
+				t.Errorf("log level %q, port %d; want %q, %d", cfg.Log.Level, cfg.Server.Port, tt.wantLevel, tt.wantPort)
+			}
+			if (len(notified) > 0) != tt.wantNotify {
+				t.Errorf("subscriber notified with %v, want notified %v", notified, tt.wantNotify)
+			}
+		})
+	}
+}
+
+func TestWatcher_ReloadsOnSIGHUP(t *testing.T) {
+	// Registering here as well keeps a SIGHUP sent before Run registers from terminating the test binary
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	w := newTestWatcher(t)
+	levels := make(chan string, 1)
+	Subscribe(w, func(c *Config) string { return c.Log.Level }, func(level string) {
+		select {
+		case levels <- level:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	t.Setenv("LOG_LEVEL", "debug")
+	// Run may not be listening yet, so the signal is repeated until the reload shows
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	deadline := time.After(5 * time.Second)
+	for {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatalf("sending SIGHUP: %v", err)
+		}
+		select {
+		case level := <-levels:
+			if level != "debug" {
+				t.Errorf("reloaded log level %q, want debug", level)
+			}
+			return
+		case <-tick.C:
+		case <-deadline:
+			t.Fatal("no reload within 5s of SIGHUP")
+		}
+	}
+}
@@ -0,0 +1,18 @@
+CREATE TABLE email_change_requests (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    confirm_token_hash TEXT UNIQUE NOT NULL,
+    revert_token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revert_expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    reverted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- At most one open request per user; a concurrent second request fails with a unique violation
+CREATE UNIQUE INDEX email_change_requests_one_pending_per_user
+    ON email_change_requests (user_id)
+    WHERE confirmed_at IS NULL AND reverted_at IS NULL;
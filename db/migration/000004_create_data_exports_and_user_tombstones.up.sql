@@ -0,0 +1,18 @@
+CREATE TABLE data_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    blob_key TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+
+-- Minimal record kept after erasure, so audits can tell an erased account from one that never existed
+CREATE TABLE user_tombstones (
+    user_id BIGINT PRIMARY KEY,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,21 @@
+-- Partitioned by month so retention is enforced by dropping whole partitions instead of mass deletes.
+-- Monthly partitions are created ahead of time by the audit service; the default partition only
+-- catches rows written before that happened.
+CREATE TABLE audit_events (
+    id BIGSERIAL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_id BIGINT, -- NULL for anonymous requests and background jobs
+    action VARCHAR(64) NOT NULL, -- e.g. user.create, user.update, user.delete
+    target_type VARCHAR(64) NOT NULL,
+    target_id BIGINT,
+    changes JSONB NOT NULL DEFAULT '{}', -- {"field": {"old": ..., "new": ...}}, sensitive fields redacted
+    request_id TEXT,
+    ip TEXT,
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+
+CREATE TABLE audit_events_default PARTITION OF audit_events DEFAULT;
+
+CREATE INDEX audit_events_target_idx ON audit_events (target_type, target_id, occurred_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id, occurred_at);
+CREATE INDEX audit_events_action_idx ON audit_events (action, occurred_at);
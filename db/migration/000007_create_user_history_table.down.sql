@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS users_history_delete ON users;
+DROP TRIGGER IF EXISTS users_history_update ON users;
+DROP FUNCTION IF EXISTS record_user_history();
+DROP TABLE IF EXISTS user_history;
@@ -0,0 +1,48 @@
+-- Every prior version of a users row, valid in [valid_from, valid_to).
+-- Written by a trigger, so updates from any code path (COPY, CTEs, manual fixes) are captured
+-- in the same transaction as the change itself. Password hashes are deliberately not kept.
+CREATE TABLE user_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    avatar_key TEXT,
+    is_admin BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    valid_from TIMESTAMPTZ NOT NULL,
+    valid_to TIMESTAMPTZ NOT NULL,
+    operation VARCHAR(8) NOT NULL -- update or delete
+);
+
+CREATE INDEX user_history_user_id_valid_to_idx ON user_history (user_id, valid_to);
+
+CREATE FUNCTION record_user_history() RETURNS trigger AS $$
+DECLARE
+    version_start TIMESTAMPTZ;
+BEGIN
+    -- The old version became current when the previous one ended, or when the user was created
+    SELECT MAX(valid_to) INTO version_start FROM user_history WHERE user_id = OLD.id;
+
+    INSERT INTO user_history (
+        user_id, first_name, last_name, email, avatar_key, is_admin, created_at, updated_at,
+        valid_from, valid_to, operation
+    ) VALUES (
+        OLD.id, OLD.first_name, OLD.last_name, OLD.email, OLD.avatar_key, OLD.is_admin, OLD.created_at, OLD.updated_at,
+        COALESCE(version_start, OLD.created_at), NOW(), LOWER(TG_OP)
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_history_update
+AFTER UPDATE ON users
+FOR EACH ROW
+WHEN (OLD IS DISTINCT FROM NEW)
+EXECUTE FUNCTION record_user_history();
+
+CREATE TRIGGER users_history_delete
+AFTER DELETE ON users
+FOR EACH ROW
+EXECUTE FUNCTION record_user_history();
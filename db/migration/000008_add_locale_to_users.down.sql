@@ -0,0 +1,21 @@
+-- Restores the function of 000007 before the column it copies is dropped
+CREATE OR REPLACE FUNCTION record_user_history() RETURNS trigger AS $$
+DECLARE
+    version_start TIMESTAMPTZ;
+BEGIN
+    -- The old version became current when the previous one ended, or when the user was created
+    SELECT MAX(valid_to) INTO version_start FROM user_history WHERE user_id = OLD.id;
+
+    INSERT INTO user_history (
+        user_id, first_name, last_name, email, avatar_key, is_admin, created_at, updated_at,
+        valid_from, valid_to, operation
+    ) VALUES (
+        OLD.id, OLD.first_name, OLD.last_name, OLD.email, OLD.avatar_key, OLD.is_admin, OLD.created_at, OLD.updated_at,
+        COALESCE(version_start, OLD.created_at), NOW(), LOWER(TG_OP)
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE user_history DROP COLUMN IF EXISTS locale;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,23 @@
+-- Preferred language for messages, a BCP 47 tag such as "de"; NULL follows Accept-Language
+ALTER TABLE users ADD COLUMN locale VARCHAR(35);
+
+ALTER TABLE user_history ADD COLUMN locale VARCHAR(35);
+
+-- Replaces the function of 000007 so prior versions keep their locale
+CREATE OR REPLACE FUNCTION record_user_history() RETURNS trigger AS $$
+DECLARE
+    version_start TIMESTAMPTZ;
+BEGIN
+    -- The old version became current when the previous one ended, or when the user was created
+    SELECT MAX(valid_to) INTO version_start FROM user_history WHERE user_id = OLD.id;
+
+    INSERT INTO user_history (
+        user_id, first_name, last_name, email, avatar_key, is_admin, locale, created_at, updated_at,
+        valid_from, valid_to, operation
+    ) VALUES (
+        OLD.id, OLD.first_name, OLD.last_name, OLD.email, OLD.avatar_key, OLD.is_admin, OLD.locale, OLD.created_at, OLD.updated_at,
+        COALESCE(version_start, OLD.created_at), NOW(), LOWER(TG_OP)
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
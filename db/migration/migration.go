@@ -0,0 +1,31 @@
+// Package migration embeds the SQL migrations, numbered for golang-migrate as NNNNNN_name.up.sql
+// and NNNNNN_name.down.sql.
+package migration
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// FS holds the migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the number of the newest migration in fsys, the version a fully migrated database reports
+func LatestVersion(fsys fs.FS) uint {
+	entries, _ := fs.ReadDir(fsys, ".")
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if version, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	return latest
+}
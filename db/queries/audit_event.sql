@@ -0,0 +1,39 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    actor_id,
+    action,
+    target_type,
+    target_id,
+    changes,
+    request_id,
+    ip
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+-- Every filter is optional; bounding occurred_at lets Postgres prune partitions.
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor_id)::bigint IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::bigint IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(occurred_after)::timestamptz IS NULL OR occurred_at >= sqlc.narg(occurred_after))
+  AND (sqlc.narg(occurred_before)::timestamptz IS NULL OR occurred_at < sqlc.narg(occurred_before))
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: ListAuditEventsForUser :many
+-- Events about the user or performed by the user, for data exports.
+SELECT * FROM audit_events
+WHERE (target_type = 'user' AND target_id = sqlc.arg(user_id)::bigint)
+   OR actor_id = sqlc.arg(user_id)::bigint
+ORDER BY occurred_at, id;
+
+-- name: RedactAuditEventsForUser :exec
+-- Erasure keeps who did what and when, but drops the personal data in diffs and IP addresses.
+UPDATE audit_events
+SET changes = CASE WHEN target_type = 'user' AND target_id = sqlc.arg(user_id)::bigint THEN '{}'::jsonb ELSE changes END,
+    ip = NULL
+WHERE (target_type = 'user' AND target_id = sqlc.arg(user_id)::bigint)
+   OR actor_id = sqlc.arg(user_id)::bigint;
@@ -0,0 +1,58 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (
+    user_id
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: GetDataExport :one
+SELECT * FROM data_exports
+WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: ListDataExportsByUser :many
+SELECT * FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: StartDataExport :one
+UPDATE data_exports
+SET status = 'running'
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET
+    status = 'completed',
+    blob_key = $2,
+    completed_at = NOW(),
+    expires_at = $3
+WHERE id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET
+    status = 'failed',
+    error = $2,
+    completed_at = NOW()
+WHERE id = $1;
+
+-- name: FailStaleDataExports :exec
+UPDATE data_exports
+SET
+    status = 'failed',
+    error = 'interrupted by restart',
+    completed_at = NOW()
+WHERE status IN ('pending', 'running') AND created_at < $1;
+
+-- name: ListExpiredDataExports :many
+SELECT * FROM data_exports
+WHERE status = 'completed' AND expires_at < NOW();
+
+-- name: DeleteDataExport :exec
+DELETE FROM data_exports
+WHERE id = $1;
+
+-- name: DeleteDataExportsByUser :exec
+DELETE FROM data_exports
+WHERE user_id = $1;
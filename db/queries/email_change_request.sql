@@ -0,0 +1,71 @@
+-- name: CancelPendingEmailChanges :exec
+UPDATE email_change_requests
+SET reverted_at = NOW()
+WHERE user_id = $1 AND confirmed_at IS NULL AND reverted_at IS NULL;
+
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests (
+    user_id,
+    old_email,
+    new_email,
+    confirm_token_hash,
+    revert_token_hash,
+    expires_at,
+    revert_expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ConfirmEmailChange :one
+-- Marks the request confirmed and swaps the address in one statement, so a unique
+-- violation on users.email rolls back both. The old_email guard skips stale requests.
+WITH confirmed AS (
+    UPDATE email_change_requests r
+    SET confirmed_at = NOW()
+    FROM users u
+    WHERE r.confirm_token_hash = $1
+      AND r.confirmed_at IS NULL
+      AND r.reverted_at IS NULL
+      AND r.expires_at > NOW()
+      AND u.id = r.user_id
+      AND u.email = r.old_email
+    RETURNING r.user_id, r.new_email
+)
+UPDATE users
+SET
+    email = confirmed.new_email,
+    updated_at = NOW()
+FROM confirmed
+WHERE users.id = confirmed.user_id
+RETURNING users.*;
+
+-- name: RevertEmailChange :one
+-- Cancels a pending request, or restores the old address of a confirmed one.
+WITH reverted AS (
+    UPDATE email_change_requests
+    SET reverted_at = NOW()
+    WHERE revert_token_hash = $1
+      AND reverted_at IS NULL
+      AND revert_expires_at > NOW()
+    RETURNING user_id, old_email, new_email, confirmed_at
+), restored AS (
+    UPDATE users
+    SET
+        email = reverted.old_email,
+        updated_at = NOW()
+    FROM reverted
+    WHERE users.id = reverted.user_id
+      AND reverted.confirmed_at IS NOT NULL
+      AND users.email = reverted.new_email
+    RETURNING users.id
+)
+SELECT user_id, old_email FROM reverted;
+
+-- name: ListEmailChangeRequestsByUser :many
+SELECT * FROM email_change_requests
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteEmailChangeRequestsByUser :exec
+DELETE FROM email_change_requests
+WHERE user_id = $1;
@@ -3,9 +3,10 @@ INSERT INTO users (
     first_name,
     last_name,
     email,
-    hashed_password
+    hashed_password,
+    is_admin
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetUserByID :one
@@ -16,19 +17,36 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
 
+-- name: GetUsersByIDs :many
+SELECT * FROM users
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: GetUsersByEmails :many
+SELECT * FROM users
+WHERE email = ANY(sqlc.arg(emails)::text[]);
+
 -- name: ListUsers :many
+-- Every filter is optional; repository.userFilterSQL mirrors this WHERE clause for exports.
 SELECT * FROM users
-ORDER BY created_at DESC
-LIMIT $1
-OFFSET $2;
+WHERE (sqlc.narg(email)::text IS NULL OR email = sqlc.narg(email))
+  AND (sqlc.narg(search)::text IS NULL
+       OR first_name ILIKE '%' || sqlc.narg(search) || '%'
+       OR last_name ILIKE '%' || sqlc.narg(search) || '%'
+       OR email ILIKE '%' || sqlc.narg(search) || '%')
+  AND (sqlc.narg(is_admin)::boolean IS NULL OR is_admin = sqlc.narg(is_admin))
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after))
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit)
+OFFSET sqlc.arg(row_offset);
 
 -- name: UpdateUser :one
 UPDATE users
 SET
     first_name = COALESCE(sqlc.narg(first_name), first_name),
     last_name = COALESCE(sqlc.narg(last_name), last_name),
-    email = COALESCE(sqlc.narg(email), email),
     hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
+    locale = COALESCE(sqlc.narg(locale), locale),
     updated_at = NOW()
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -36,3 +54,44 @@ RETURNING *;
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
+
+-- name: UpdateUserAvatar :one
+UPDATE users
+SET
+    avatar_key = sqlc.narg(avatar_key),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetUserAdmin :one
+UPDATE users
+SET
+    is_admin = sqlc.arg(is_admin),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: EraseUser :exec
+-- Deletes the user row and leaves a tombstone in the same statement.
+WITH erased AS (
+    DELETE FROM users
+    WHERE id = $1
+    RETURNING id
+)
+INSERT INTO user_tombstones (user_id)
+SELECT id FROM erased;
+
+-- name: CopyUsers :copyfrom
+INSERT INTO users (
+    first_name,
+    last_name,
+    email,
+    hashed_password,
+    is_admin
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ListExistingEmails :many
+SELECT email FROM users
+WHERE email = ANY(sqlc.arg(emails)::text[]);
@@ -0,0 +1,16 @@
+-- name: ListUserHistory :many
+-- Prior versions of a user, newest first. The current version lives in users.
+SELECT * FROM user_history
+WHERE user_id = $1
+ORDER BY valid_to DESC, history_id DESC;
+
+-- name: GetUserHistoryAsOf :one
+-- The prior version that was current at the given time, if any.
+SELECT * FROM user_history
+WHERE user_id = sqlc.arg(user_id) AND valid_from <= sqlc.arg(as_of) AND valid_to > sqlc.arg(as_of)
+ORDER BY history_id DESC
+LIMIT 1;
+
+-- name: DeleteUserHistory :exec
+DELETE FROM user_history
+WHERE user_id = $1;
@@ -6,14 +6,77 @@ package sqlc
 
 import (
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AuditEvent struct {
+	ID         int64       `json:"id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	ActorID    pgtype.Int8 `json:"actor_id"`
+	Action     string      `json:"action"`
+	TargetType string      `json:"target_type"`
+	TargetID   pgtype.Int8 `json:"target_id"`
+	Changes    []byte      `json:"changes"`
+	RequestID  pgtype.Text `json:"request_id"`
+	Ip         pgtype.Text `json:"ip"`
+}
+
+type DataExport struct {
+	ID          int64              `json:"id"`
+	UserID      int64              `json:"user_id"`
+	Status      string             `json:"status"`
+	BlobKey     pgtype.Text        `json:"blob_key"`
+	Error       pgtype.Text        `json:"error"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+}
+
+type EmailChangeRequest struct {
+	ID               int64              `json:"id"`
+	UserID           int64              `json:"user_id"`
+	OldEmail         string             `json:"old_email"`
+	NewEmail         string             `json:"new_email"`
+	ConfirmTokenHash string             `json:"confirm_token_hash"`
+	RevertTokenHash  string             `json:"revert_token_hash"`
+	ExpiresAt        time.Time          `json:"expires_at"`
+	RevertExpiresAt  time.Time          `json:"revert_expires_at"`
+	ConfirmedAt      pgtype.Timestamptz `json:"confirmed_at"`
+	RevertedAt       pgtype.Timestamptz `json:"reverted_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
 type User struct {
-	ID             int64     `json:"id"`
-	FirstName      string    `json:"first_name"`
-	LastName       string    `json:"last_name"`
-	Email          string    `json:"email"`
-	HashedPassword string    `json:"hashed_password"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             int64       `json:"id"`
+	FirstName      string      `json:"first_name"`
+	LastName       string      `json:"last_name"`
+	Email          string      `json:"email"`
+	HashedPassword string      `json:"hashed_password"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	AvatarKey      pgtype.Text `json:"avatar_key"`
+	IsAdmin        bool        `json:"is_admin"`
+	Locale         pgtype.Text `json:"locale"`
+}
+
+type UserHistory struct {
+	HistoryID int64       `json:"history_id"`
+	UserID    int64       `json:"user_id"`
+	FirstName string      `json:"first_name"`
+	LastName  string      `json:"last_name"`
+	Email     string      `json:"email"`
+	AvatarKey pgtype.Text `json:"avatar_key"`
+	IsAdmin   bool        `json:"is_admin"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	ValidFrom time.Time   `json:"valid_from"`
+	ValidTo   time.Time   `json:"valid_to"`
+	Operation string      `json:"operation"`
+	Locale    pgtype.Text `json:"locale"`
+}
+
+type UserTombstone struct {
+	UserID   int64     `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
 }
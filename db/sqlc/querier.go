@@ -6,15 +6,56 @@ package sqlc
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	CancelPendingEmailChanges(ctx context.Context, userID int64) error
+	CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) error
+	// Marks the request confirmed and swaps the address in one statement, so a unique
+	// violation on users.email rolls back both. The old_email guard skips stale requests.
+	ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (User, error)
+	CopyUsers(ctx context.Context, arg []CopyUsersParams) (int64, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateDataExport(ctx context.Context, userID int64) (DataExport, error)
+	CreateEmailChangeRequest(ctx context.Context, arg CreateEmailChangeRequestParams) (EmailChangeRequest, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteDataExport(ctx context.Context, id int64) error
+	DeleteDataExportsByUser(ctx context.Context, userID int64) error
+	DeleteEmailChangeRequestsByUser(ctx context.Context, userID int64) error
 	DeleteUser(ctx context.Context, id int64) error
+	DeleteUserHistory(ctx context.Context, userID int64) error
+	// Deletes the user row and leaves a tombstone in the same statement.
+	EraseUser(ctx context.Context, id int64) error
+	FailDataExport(ctx context.Context, arg FailDataExportParams) error
+	FailStaleDataExports(ctx context.Context, createdAt time.Time) error
+	GetDataExport(ctx context.Context, arg GetDataExportParams) (DataExport, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int64) (User, error)
+	// The prior version that was current at the given time, if any.
+	GetUserHistoryAsOf(ctx context.Context, arg GetUserHistoryAsOfParams) (UserHistory, error)
+	GetUsersByEmails(ctx context.Context, emails []string) ([]User, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]User, error)
+	// Every filter is optional; bounding occurred_at lets Postgres prune partitions.
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// Events about the user or performed by the user, for data exports.
+	ListAuditEventsForUser(ctx context.Context, userID int64) ([]AuditEvent, error)
+	ListDataExportsByUser(ctx context.Context, userID int64) ([]DataExport, error)
+	ListEmailChangeRequestsByUser(ctx context.Context, userID int64) ([]EmailChangeRequest, error)
+	ListExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	ListExpiredDataExports(ctx context.Context) ([]DataExport, error)
+	// Prior versions of a user, newest first. The current version lives in users.
+	ListUserHistory(ctx context.Context, userID int64) ([]UserHistory, error)
+	// Every filter is optional; repository.userFilterSQL mirrors this WHERE clause for exports.
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	// Erasure keeps who did what and when, but drops the personal data in diffs and IP addresses.
+	RedactAuditEventsForUser(ctx context.Context, userID int64) error
+	// Cancels a pending request, or restores the old address of a confirmed one.
+	RevertEmailChange(ctx context.Context, revertTokenHash string) (RevertEmailChangeRow, error)
+	SetUserAdmin(ctx context.Context, arg SetUserAdminParams) (User, error)
+	StartDataExport(ctx context.Context, id int64) (DataExport, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
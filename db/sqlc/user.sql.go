@@ -11,15 +11,24 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type CopyUsersParams struct {
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	Email          string `json:"email"`
+	HashedPassword string `json:"hashed_password"`
+	IsAdmin        bool   `json:"is_admin"`
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     first_name,
     last_name,
     email,
-    hashed_password
+    hashed_password,
+    is_admin
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at
+    $1, $2, $3, $4, $5
+) RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale
 `
 
 type CreateUserParams struct {
@@ -27,6 +36,7 @@ type CreateUserParams struct {
 	LastName       string `json:"last_name"`
 	Email          string `json:"email"`
 	HashedPassword string `json:"hashed_password"`
+	IsAdmin        bool   `json:"is_admin"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -35,6 +45,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.LastName,
 		arg.Email,
 		arg.HashedPassword,
+		arg.IsAdmin,
 	)
 	var i User
 	err := row.Scan(
@@ -45,6 +56,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -59,8 +73,24 @@ func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
 	return err
 }
 
+const eraseUser = `-- name: EraseUser :exec
+WITH erased AS (
+    DELETE FROM users
+    WHERE id = $1
+    RETURNING id
+)
+INSERT INTO user_tombstones (user_id)
+SELECT id FROM erased
+`
+
+// Deletes the user row and leaves a tombstone in the same statement.
+func (q *Queries) EraseUser(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, eraseUser, id)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at FROM users
+SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -75,12 +105,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at FROM users
+SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -95,24 +128,146 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
 	)
 	return i, err
 }
 
+const getUsersByEmails = `-- name: GetUsersByEmails :many
+SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale FROM users
+WHERE email = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByEmails(ctx context.Context, emails []string) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByEmails, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AvatarKey,
+			&i.IsAdmin,
+			&i.Locale,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale FROM users
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []int64) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AvatarKey,
+			&i.IsAdmin,
+			&i.Locale,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExistingEmails = `-- name: ListExistingEmails :many
+SELECT email FROM users
+WHERE email = ANY($1::text[])
+`
+
+func (q *Queries) ListExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listExistingEmails, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at FROM users
-ORDER BY created_at DESC
-LIMIT $1
-OFFSET $2
+SELECT id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale FROM users
+WHERE ($1::text IS NULL OR email = $1)
+  AND ($2::text IS NULL
+       OR first_name ILIKE '%' || $2 || '%'
+       OR last_name ILIKE '%' || $2 || '%'
+       OR email ILIKE '%' || $2 || '%')
+  AND ($3::boolean IS NULL OR is_admin = $3)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+OFFSET $7
 `
 
 type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Email         pgtype.Text        `json:"email"`
+	Search        pgtype.Text        `json:"search"`
+	IsAdmin       pgtype.Bool        `json:"is_admin"`
+	CreatedAfter  pgtype.Timestamptz `json:"created_after"`
+	CreatedBefore pgtype.Timestamptz `json:"created_before"`
+	RowLimit      int32              `json:"row_limit"`
+	RowOffset     int32              `json:"row_offset"`
 }
 
+// Every filter is optional; repository.userFilterSQL mirrors this WHERE clause for exports.
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
-	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Email,
+		arg.Search,
+		arg.IsAdmin,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +283,9 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.HashedPassword,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AvatarKey,
+			&i.IsAdmin,
+			&i.Locale,
 		); err != nil {
 			return nil, err
 		}
@@ -139,23 +297,55 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const setUserAdmin = `-- name: SetUserAdmin :one
+UPDATE users
+SET
+    is_admin = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale
+`
+
+type SetUserAdminParams struct {
+	IsAdmin bool  `json:"is_admin"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) SetUserAdmin(ctx context.Context, arg SetUserAdminParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserAdmin, arg.IsAdmin, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
     first_name = COALESCE($1, first_name),
     last_name = COALESCE($2, last_name),
-    email = COALESCE($3, email),
-    hashed_password = COALESCE($4, hashed_password),
+    hashed_password = COALESCE($3, hashed_password),
+    locale = COALESCE($4, locale),
     updated_at = NOW()
 WHERE id = $5
-RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at
+RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale
 `
 
 type UpdateUserParams struct {
 	FirstName      pgtype.Text `json:"first_name"`
 	LastName       pgtype.Text `json:"last_name"`
-	Email          pgtype.Text `json:"email"`
 	HashedPassword pgtype.Text `json:"hashed_password"`
+	Locale         pgtype.Text `json:"locale"`
 	ID             int64       `json:"id"`
 }
 
@@ -163,8 +353,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.FirstName,
 		arg.LastName,
-		arg.Email,
 		arg.HashedPassword,
+		arg.Locale,
 		arg.ID,
 	)
 	var i User
@@ -176,6 +366,41 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
+	)
+	return i, err
+}
+
+const updateUserAvatar = `-- name: UpdateUserAvatar :one
+UPDATE users
+SET
+    avatar_key = $1,
+    updated_at = NOW()
+WHERE id = $2
+RETURNING id, first_name, last_name, email, hashed_password, created_at, updated_at, avatar_key, is_admin, locale
+`
+
+type UpdateUserAvatarParams struct {
+	AvatarKey pgtype.Text `json:"avatar_key"`
+	ID        int64       `json:"id"`
+}
+
+func (q *Queries) UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserAvatar, arg.AvatarKey, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AvatarKey,
+		&i.IsAdmin,
+		&i.Locale,
 	)
 	return i, err
 }
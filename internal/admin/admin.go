@@ -0,0 +1,158 @@
+// Package admin serves the operational endpoints of the admin server: metrics, profiling, build
+// information, the effective configuration, the route table and the runtime log level.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/yourusername/yourprojectname/internal/buildinfo"
+	"github.com/yourusername/yourprojectname/internal/health"
+	"github.com/yourusername/yourprojectname/internal/middleware"
+)
+
+// Options configures NewHandler
+type Options struct {
+	Metrics  http.Handler          // served on /metrics without authentication, for Prometheus
+	Token    func() string         // bearer token for /debug; empty allows only clients on the loopback interface
+	Config   func() map[string]any // effective configuration with secrets redacted
+	Routes   func() []Route
+	LogLevel *slog.LevelVar
+	Health   *health.Registry
+}
+
+// NewHandler creates the admin server's handler:
+//
+//	GET /metrics              Prometheus metrics
+//	GET /debug/pprof/         net/http/pprof profiles
+//	GET /debug/buildinfo      version, commit and Go version of the binary
+//	GET /debug/config         effective configuration, secrets redacted
+//	GET /debug/routes         API routes with their middleware
+//	GET /debug/health         startup and readiness reports including the errors of failing components
+//	GET|PUT /debug/loglevel   read or change the log level until the next restart or config reload changing it
+func NewHandler(opts Options) http.Handler {
+	debug := http.NewServeMux()
+	debug.HandleFunc("/debug/pprof/", pprof.Index)
+	debug.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	debug.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	debug.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	debug.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	debug.HandleFunc("/debug/buildinfo", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, buildinfo.Get())
+	}))
+	debug.HandleFunc("/debug/config", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, opts.Config())
+	}))
+	debug.HandleFunc("/debug/routes", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, opts.Routes())
+	}))
+	debug.HandleFunc("/debug/health", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		startup, readiness := opts.Health.Started(r.Context()), opts.Health.Ready(r.Context())
+		status := http.StatusOK
+		if !startup.OK() || !readiness.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, map[string]health.Report{"startup": startup, "readiness": readiness})
+	}))
+	debug.HandleFunc("/debug/loglevel", logLevelHandler(opts.LogLevel))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", opts.Metrics)
+	mux.Handle("/debug/", requireOperator(opts.Token, debug))
+	return mux
+}
+
+// requireOperator admits requests bearing the token or, while no token is configured, requests from
+// the loopback interface. The admin port is often reachable from the cluster for scraping, while
+// profiles and the configuration must stay with operators.
+func requireOperator(token func() string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := token()
+		if want == "" {
+			if !isLoopback(r.RemoteAddr) {
+				writeProblem(w, http.StatusForbidden, "Access denied", "debug endpoints are only available on localhost unless ADMIN_TOKEN is set")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeProblem(w, http.StatusUnauthorized, "Authentication required", "missing or invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// logLevelHandler reports the level on GET and sets it from {"level": "debug"} on PUT
+func logLevelHandler(level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body struct {
+				Level string `json:"level"`
+			}
+			var next slog.Level
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&body); err != nil {
+				writeProblem(w, http.StatusBadRequest, "Invalid request", "expected a JSON body such as {\"level\": \"debug\"}")
+				return
+			}
+			if err := next.UnmarshalText([]byte(body.Level)); err != nil {
+				writeProblem(w, http.StatusBadRequest, "Invalid request", fmt.Sprintf("unknown log level %q, expected debug, info, warn or error", body.Level))
+				return
+			}
+			level.Set(next)
+			slog.Warn("Log level changed through the admin server", slog.String("level", next.String()))
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"level": level.Level().String()})
+	}
+}
+
+func getOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeProblem(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeProblem answers with an RFC 9457 problem like the API; the admin server is not localized
+func writeProblem(w http.ResponseWriter, status int, title, detail string) {
+	w.Header().Set("Content-Type", middleware.ProblemContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(middleware.Problem{Type: "about:blank", Title: title, Status: status, Detail: detail})
+}
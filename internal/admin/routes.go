@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route describes a registered Gin route
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+}
+
+// GinRoutes lists the routes of engine with the middleware in front of each handler, in order.
+// Gin only exposes the final handler, so the chains are read from its routing trees; if their layout
+// changes in a future Gin version, the routes are listed without middleware.
+func GinRoutes(engine *gin.Engine) []Route {
+	routes, ok := routesFromTrees(engine)
+	if !ok {
+		routes = nil
+		for _, info := range engine.Routes() {
+			routes = append(routes, Route{Method: info.Method, Path: info.Path, Handler: shortFuncName(info.Handler)})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// routesFromTrees walks gin.Engine.trees ([]methodTree{method, root *node}); nodes hold fullPath,
+// handlers and children
+func routesFromTrees(engine *gin.Engine) (routes []Route, ok bool) {
+	defer func() {
+		if recover() != nil {
+			routes, ok = nil, false
+		}
+	}()
+	trees := reflect.ValueOf(engine).Elem().FieldByName("trees")
+	if !trees.IsValid() || trees.Kind() != reflect.Slice {
+		return nil, false
+	}
+	var walk func(method string, node reflect.Value)
+	walk = func(method string, node reflect.Value) {
+		if node.Kind() != reflect.Pointer || node.IsNil() {
+			return
+		}
+		n := node.Elem()
+		if handlers := n.FieldByName("handlers"); handlers.Len() > 0 {
+			names := make([]string, handlers.Len())
+			for i := range names {
+				names[i] = shortFuncName(runtime.FuncForPC(handlers.Index(i).Pointer()).Name())
+			}
+			routes = append(routes, Route{
+				Method:     method,
+				Path:       n.FieldByName("fullPath").String(),
+				Handler:    names[len(names)-1],
+				Middleware: names[:len(names)-1],
+			})
+		}
+		children := n.FieldByName("children")
+		for i := 0; i < children.Len(); i++ {
+			walk(method, children.Index(i))
+		}
+	}
+	for i := 0; i < trees.Len(); i++ {
+		tree := trees.Index(i)
+		walk(tree.FieldByName("method").String(), tree.FieldByName("root"))
+	}
+	return routes, true
+}
+
+// closureSuffix matches the names the compiler gives closures and method values
+var closureSuffix = regexp.MustCompile(`(\.func\d+(\.\d+)*)+$|-fm$`)
+
+// shortFuncName turns "github.com/x/y/internal/middleware.RequestID.func1" into "middleware.RequestID"
+func shortFuncName(name string) string {
+	name = name[strings.LastIndex(name, "/")+1:]
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,148 @@
+// Package app composes the application from modules. Each module, such as users or the audit log,
+// builds its repositories and services from the shared dependencies and registers its routes,
+// migrations, health checks and background jobs with the App.
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/health"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/lifecycle"
+	"github.com/yourusername/yourprojectname/internal/mailer"
+	"github.com/yourusername/yourprojectname/internal/metrics"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+	"github.com/yourusername/yourprojectname/internal/storage"
+)
+
+// Module is a feature of the application, e.g. a resource with its repository, service, handler and routes.
+type Module interface {
+	// Name identifies the module in logs and errors
+	Name() string
+	// Register builds the module's components from the dependencies of a and the services provided by
+	// the modules registered before it, and provides those later modules need.
+	Register(a *App) error
+}
+
+// Deps are the shared dependencies of all modules, opened and closed by the caller.
+type Deps struct {
+	Config    *config.Config
+	Watcher   *config.Watcher // applies the settings tagged `reload` to subscribers
+	DB        *pgxpool.Pool
+	Redis     *redis.Client
+	BlobStore storage.BlobStore
+	Mailer    mailer.Mailer
+	I18n      *i18n.Bundle
+	Metrics   *metrics.Registry
+	Health    *health.Registry
+	Lifecycle *lifecycle.Manager
+}
+
+// Routes are the groups modules register their routes on
+type Routes struct {
+	API  *gin.RouterGroup // /api/v1, behind the rate limit
+	Root gin.IRoutes      // outside /api/v1, e.g. for probes
+}
+
+// App holds the dependencies and what the registered modules contributed.
+type App struct {
+	Deps
+	Queries *sqlc.Queries
+	// Privacy collects the components storing personal data; register parents before children
+	Privacy *privacy.Registry
+
+	services      map[reflect.Type]any
+	routes        []func(Routes) error
+	migrations    []fs.FS
+	afterRegister []func() error
+}
+
+// New creates an App without modules.
+func New(deps Deps) *App {
+	return &App{
+		Deps:     deps,
+		Queries:  sqlc.New(deps.DB),
+		Privacy:  privacy.NewRegistry(),
+		services: make(map[reflect.Type]any),
+	}
+}
+
+// Register registers modules in order, so a module may use the services of those before it.
+// Once all are registered, the functions passed to AfterRegister run.
+func (a *App) Register(modules ...Module) error {
+	for _, m := range modules {
+		if err := m.Register(a); err != nil {
+			return fmt.Errorf("module %s: %w", m.Name(), err)
+		}
+		slog.Info("Module registered", slog.String("module", m.Name()))
+	}
+	for _, fn := range a.afterRegister {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	a.afterRegister = nil
+	return nil
+}
+
+// AfterRegister defers fn until every module passed to Register is registered, e.g. to act after modules registered later.
+func (a *App) AfterRegister(fn func() error) {
+	a.afterRegister = append(a.afterRegister, fn)
+}
+
+// AddRoutes defers registering routes until Mount, after the global middlewares are in place.
+func (a *App) AddRoutes(fn func(r Routes) error) {
+	a.routes = append(a.routes, fn)
+}
+
+// Mount registers the routes of every module.
+func (a *App) Mount(r Routes) error {
+	for _, fn := range a.routes {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMigrations adds the golang-migrate files (NNNNNN_name.up.sql and .down.sql) in the root of fsys.
+// Versions are global, so they must not collide with those of other modules.
+func (a *App) AddMigrations(fsys fs.FS) {
+	a.migrations = append(a.migrations, fsys)
+}
+
+// Migrations returns the migration files of every module as one file system.
+func (a *App) Migrations() fs.FS {
+	return mergedFS(a.migrations)
+}
+
+// AddJob runs fn in the background from the start of the lifecycle until it stops, before the databases close.
+func (a *App) AddJob(name string, fn func(ctx context.Context)) {
+	a.Lifecycle.Append(lifecycle.Worker(name, fn))
+}
+
+// Provide makes v available to the modules registered later as T, usually an interface type.
+func Provide[T any](a *App, v T) {
+	a.services[reflect.TypeFor[T]()] = v
+}
+
+// Get returns the T provided by an earlier module.
+func Get[T any](a *App) (T, error) {
+	typ := reflect.TypeFor[T]()
+	v, ok := a.services[typ]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("no %s provided; register the module providing it first", typ)
+	}
+	t, _ := v.(T) // a nil interface value was provided
+	return t, nil
+}
@@ -0,0 +1,130 @@
+// The tests compose the built-in modules, which import this package, so they live in app_test.
+package app_test
+
+import (
+	"context"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/health"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/lifecycle"
+	"github.com/yourusername/yourprojectname/internal/metrics"
+	"github.com/yourusername/yourprojectname/internal/modules"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// newTestApp creates an App on the default configuration. The pool points nowhere; it connects
+// lazily, and modules do not query on registration, so no database is needed.
+func newTestApp(t *testing.T) *app.App {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://app@127.0.0.1:1/app?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	bundle, err := i18n.Load()
+	if err != nil {
+		t.Fatalf("i18n.Load: %v", err)
+	}
+
+	cfg := config.Defaults()
+	return app.New(app.Deps{
+		Config:    &cfg,
+		Watcher:   config.NewWatcher(config.NewLoader(flag.NewFlagSet("test", flag.ContinueOnError)), &cfg),
+		DB:        pool,
+		I18n:      bundle,
+		Metrics:   metrics.NewRegistry(),
+		Health:    health.NewRegistry(cfg.Health.CheckTimeout),
+		Lifecycle: lifecycle.New(),
+	})
+}
+
+func TestRegister_SubsetOfModules(t *testing.T) {
+	a := newTestApp(t)
+
+	if err := a.Register(modules.NewAuditModule(), modules.NewUsersModule()); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if userService, err := app.Get[service.UserService](a); err != nil || userService == nil {
+		t.Errorf("Get[service.UserService] = %v, %v; want the service of the users module", userService, err)
+	}
+	if userRepo, err := app.Get[repository.UserRepository](a); err != nil || userRepo == nil {
+		t.Errorf("Get[repository.UserRepository] = %v, %v; want the repository of the users module", userRepo, err)
+	}
+	auditService, err := app.Get[service.AuditService](a)
+	if err != nil || auditService == nil {
+		t.Errorf("Get[service.AuditService] = %v, %v; want the service of the audit module", auditService, err)
+	}
+	if logger, err := app.Get[service.AuditLogger](a); err != nil || logger != service.AuditLogger(auditService) {
+		t.Errorf("Get[service.AuditLogger] = %v, %v; want the audit service", logger, err)
+	}
+
+	// Only the routes of the registered modules are mounted
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := a.Mount(app.Routes{API: router.Group("/api/v1"), Root: router}); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	var paths []string
+	for _, route := range router.Routes() {
+		paths = append(paths, route.Path)
+	}
+	if !slices.Contains(paths, "/api/v1/users/:id") {
+		t.Errorf("routes %v lack those of the users module", paths)
+	}
+	for _, path := range paths {
+		if strings.Contains(path, "email-change") {
+			t.Errorf("route %s of the unregistered email change module is mounted", path)
+		}
+	}
+}
+
+func TestRegister_MissingDependency(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []app.Module
+		wantErr string
+	}{
+		{
+			name:    "users without audit",
+			modules: []app.Module{modules.NewUsersModule()},
+			wantErr: "module users: no service.AuditLogger provided; register the module providing it first",
+		},
+		{
+			name:    "email change without users",
+			modules: []app.Module{modules.NewAuditModule(), modules.NewEmailChangeModule()},
+			wantErr: "module email change: no repository.UserRepository provided; register the module providing it first",
+		},
+		{
+			name:    "users before audit",
+			modules: []app.Module{modules.NewUsersModule(), modules.NewAuditModule()},
+			wantErr: "module users: no service.AuditLogger provided",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestApp(t).Register(tt.modules...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Register = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGet_NotProvided(t *testing.T) {
+	a := newTestApp(t)
+
+	_, err := app.Get[service.UserService](a)
+	if err == nil || err.Error() != "no service.UserService provided; register the module providing it first" {
+		t.Errorf("Get of a service no module provided = %v, want an error naming the service", err)
+	}
+}
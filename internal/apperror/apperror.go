@@ -0,0 +1,149 @@
+// Package apperror defines the domain errors shared by all layers.
+// Repositories translate driver errors into them, services return them, and the HTTP layer maps
+// their Kind to a status code in one place instead of every handler matching errors itself.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies a domain error. A Kind is itself an error, so errors.Is(err, apperror.NotFound)
+// reports whether err is, or wraps, a domain error of that kind.
+type Kind string
+
+const (
+	NotFound     Kind = "not_found"
+	Conflict     Kind = "conflict"
+	Validation   Kind = "validation"
+	Unauthorized Kind = "unauthorized"
+	Forbidden    Kind = "forbidden"
+	TooLarge     Kind = "too_large"    // the request body or an upload exceeds a limit
+	Unsupported  Kind = "unsupported"  // the media type of an upload is not accepted
+	Unavailable  Kind = "unavailable"  // a temporary condition; the request may be retried later
+	RateLimited  Kind = "rate_limited" // the client sent too many requests; it may retry later
+	Internal     Kind = "internal"     // a server-side failure with a client-safe message, e.g. to attach a partial result
+)
+
+func (k Kind) Error() string {
+	return string(k)
+}
+
+// Error is a domain error. Message is safe to show to clients; the wrapped Err is not.
+type Error struct {
+	Kind    Kind
+	Message string
+	// Fields lists the invalid request fields of a validation error.
+	Fields []FieldError
+	// Extensions are extra members added to the error response, e.g. a partial import report.
+	Extensions map[string]any
+	Err        error
+
+	// format and args are the untranslated message, see Localize
+	format string
+	args   []any
+}
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`  // JSON name of the field, e.g. "email" or "ids[2]"
+	Code    string `json:"code"`   // machine-readable reason, e.g. "required" or "too_short"
+	Message string `json:"detail"` // human-readable reason
+
+	format string
+	args   []any
+}
+
+// NewFieldError creates a field error with a formatted message.
+func NewFieldError(field, code, format string, args ...any) FieldError {
+	return FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...), format: format, args: args}
+}
+
+// Localize returns a copy of f with its message translated by translate, see Error.Localize.
+func (f FieldError) Localize(translate func(string) string) FieldError {
+	f.Message = localize(translate, f.Message, f.format, f.args)
+	return f
+}
+
+// New creates a domain error.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Newf creates a domain error with a formatted message.
+func Newf(kind Kind, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), format: format, args: args}
+}
+
+// Wrap creates a domain error caused by err. err stays reachable through errors.Is and errors.As
+// but is never part of Message.
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// Wrapf creates a domain error caused by err with a formatted message.
+func Wrapf(kind Kind, err error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err, format: format, args: args}
+}
+
+// Error returns the client-safe message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the Kind of e, so errors.Is(err, apperror.NotFound) works on wrapped errors.
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// Localize returns the message translated by translate, which maps an English message or format
+// string to the client's language. Formatted messages are translated before the arguments are
+// substituted, so a single catalog entry covers every value.
+func (e *Error) Localize(translate func(string) string) string {
+	return localize(translate, e.Message, e.format, e.args)
+}
+
+func localize(translate func(string) string, message, format string, args []any) string {
+	if format == "" {
+		return translate(message)
+	}
+	return fmt.Sprintf(translate(format), args...)
+}
+
+// WithFields returns a copy of e carrying the invalid request fields.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithExtension returns a copy of e with an extra response member.
+func (e *Error) WithExtension(key string, value any) *Error {
+	c := *e
+	c.Extensions = make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		c.Extensions[k] = v
+	}
+	c.Extensions[key] = value
+	return &c
+}
+
+// As returns the outermost domain error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// KindOf returns the Kind of the outermost domain error in err's chain, or "" if there is none.
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package audit
+
+import "context"
+
+// Metadata describes who performed a request and where it came from.
+// It travels in the request context so services can attribute changes without knowing about HTTP.
+type Metadata struct {
+	ActorID   int64 // 0 when the caller is not authenticated
+	RequestID string
+	IP        string
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a copy of ctx carrying md.
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFrom returns the metadata stored in ctx, or the zero value for background work.
+func MetadataFrom(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// WithActor returns a copy of ctx whose metadata names actorID as the caller.
+func WithActor(ctx context.Context, actorID int64) context.Context {
+	md := MetadataFrom(ctx)
+	md.ActorID = actorID
+	return WithMetadata(ctx, md)
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive fields in diffs.
+const Redacted = "[REDACTED]"
+
+// Change is the old and new value of a single field. Old is nil on creation, New on deletion.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// sensitiveFields never appear in audit diffs; a change to them is still recorded, with both values redacted
+var sensitiveFields = map[string]bool{
+	"password":        true,
+	"hashed_password": true,
+}
+
+// IsSensitive reports whether the values of a field must not be stored in the audit log.
+func IsSensitive(field string) bool {
+	return sensitiveFields[field] || strings.HasSuffix(field, "_token") || strings.HasSuffix(field, "_token_hash")
+}
+
+// Diff compares the JSON representations of before and after and returns the changed fields.
+// Pass nil as before for creations and as after for deletions.
+func Diff(before, after any) (map[string]Change, error) {
+	oldFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for field, oldValue := range oldFields {
+		newValue, ok := newFields[field]
+		if !ok || string(oldValue) != string(newValue) {
+			changes[field] = newChange(field, oldValue, newFields[field])
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes[field] = newChange(field, nil, newValue)
+		}
+	}
+	return changes, nil
+}
+
+func newChange(field string, oldValue, newValue json.RawMessage) Change {
+	if IsSensitive(field) {
+		var c Change
+		if oldValue != nil {
+			c.Old = Redacted
+		}
+		if newValue != nil {
+			c.New = Redacted
+		}
+		return c
+	}
+	return Change{Old: rawOrNil(oldValue), New: rawOrNil(newValue)}
+}
+
+func rawOrNil(v json.RawMessage) any {
+	if v == nil {
+		return nil
+	}
+	return v
+}
+
+func toFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
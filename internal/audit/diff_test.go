@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// account has the kinds of fields audited entities carry
+type account struct {
+	ID             int64  `json:"id"`
+	Email          string `json:"email"`
+	HashedPassword string `json:"hashed_password"`
+	ResetToken     string `json:"reset_token,omitempty"`
+	IsAdmin        bool   `json:"is_admin"`
+}
+
+const (
+	oldHash = "pbkdf2$old-secret-hash"
+	newHash = "pbkdf2$new-secret-hash"
+)
+
+func TestDiff(t *testing.T) {
+	alice := account{ID: 1, Email: "alice@example.com", HashedPassword: oldHash}
+
+	tests := []struct {
+		name   string
+		before any
+		after  any
+		want   string // the diff as stored, in JSON
+	}{
+		{name: "nothing changed", before: alice, after: alice, want: `{}`},
+		{
+			name:   "plain field",
+			before: alice,
+			after:  account{ID: 1, Email: "alice@example.org", HashedPassword: oldHash},
+			want:   `{"email":{"old":"alice@example.com","new":"alice@example.org"}}`,
+		},
+		{
+			name:   "password changed",
+			before: alice,
+			after:  account{ID: 1, Email: "alice@example.com", HashedPassword: newHash, IsAdmin: true},
+			want:   `{"hashed_password":{"old":"[REDACTED]","new":"[REDACTED]"},"is_admin":{"old":false,"new":true}}`,
+		},
+		{
+			name:   "token added",
+			before: alice,
+			after:  account{ID: 1, Email: "alice@example.com", HashedPassword: oldHash, ResetToken: "secret-token"},
+			want:   `{"reset_token":{"old":null,"new":"[REDACTED]"}}`,
+		},
+		{
+			name:   "token removed",
+			before: account{ID: 1, Email: "alice@example.com", HashedPassword: oldHash, ResetToken: "secret-token"},
+			after:  alice,
+			want:   `{"reset_token":{"old":"[REDACTED]","new":null}}`,
+		},
+		{
+			name:  "creation",
+			after: alice,
+			want:  `{"email":{"old":null,"new":"alice@example.com"},"hashed_password":{"old":null,"new":"[REDACTED]"},"id":{"old":null,"new":1},"is_admin":{"old":null,"new":false}}`,
+		},
+		{
+			name:   "deletion",
+			before: alice,
+			want:   `{"email":{"old":"alice@example.com","new":null},"hashed_password":{"old":"[REDACTED]","new":null},"id":{"old":1,"new":null},"is_admin":{"old":false,"new":null}}`,
+		},
+		{
+			name:   "maps",
+			before: map[string]any{"password": "hunter2", "email_change_token_hash": "abc", "locale": "en"},
+			after:  map[string]any{"password": "hunter3", "email_change_token_hash": "def", "locale": "de"},
+			want:   `{"email_change_token_hash":{"old":"[REDACTED]","new":"[REDACTED]"},"locale":{"old":"en","new":"de"},"password":{"old":"[REDACTED]","new":"[REDACTED]"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			data, err := json.Marshal(diff)
+			if err != nil {
+				t.Fatalf("marshaling diff: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("diff = %s\nwant %s", data, tt.want)
+			}
+			for _, secret := range []string{oldHash, newHash, "secret-token", "hunter", "abc", "def"} {
+				if strings.Contains(string(data), secret) {
+					t.Errorf("diff %s leaks %q", data, secret)
+				}
+			}
+		})
+	}
+}
+
+func TestDiff_RejectsNonObjects(t *testing.T) {
+	for _, v := range []any{42, "text", []int{1}, func() {}} {
+		if _, err := Diff(nil, v); err == nil {
+			t.Errorf("Diff(nil, %T) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		field string
+		want  bool
+	}{
+		{field: "password", want: true},
+		{field: "hashed_password", want: true},
+		{field: "reset_token", want: true},
+		{field: "email_change_token_hash", want: true},
+		{field: "email", want: false},
+		{field: "token_count", want: false},
+		{field: "password_changed_at", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsSensitive(tt.field); got != tt.want {
+			t.Errorf("IsSensitive(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package buildinfo describes the running binary. Version, Commit and Date are set at build time:
+//
+//	go build -ldflags "-X github.com/yourusername/yourprojectname/internal/buildinfo.Version=v1.2.3 \
+//		-X github.com/yourusername/yourprojectname/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/yourusername/yourprojectname/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set through -ldflags -X; see the package comment
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info is the build information of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a working tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information. Without ldflags, the commit and date come from the VCS
+// information the go command embeds when building inside a repository.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range build.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/storage"
+)
+
+// BlobHandler serves blobs from a LocalBlobStore through signed URLs.
+// S3-compatible stores hand out presigned URLs of their own and do not need it.
+type BlobHandler struct {
+	blobStore *storage.LocalBlobStore
+}
+
+// NewBlobHandler creates a new BlobHandler.
+func NewBlobHandler(blobStore *storage.LocalBlobStore) *BlobHandler {
+	return &BlobHandler{
+		blobStore: blobStore,
+	}
+}
+
+// GetBlob streams a blob after verifying the URL signature.
+// GET /api/v1/blobs/*key?expires=...&signature=...
+func (h *BlobHandler) GetBlob(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.blobStore.VerifySignature(key, c.Query("expires"), c.Query("signature")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	blob, err := h.blobStore.Get(c.Request.Context(), key)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer blob.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, blob)
+}
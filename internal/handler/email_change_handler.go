@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// EmailChangeHandler handles HTTP requests for the email change flow.
+type EmailChangeHandler struct {
+	emailChangeService service.EmailChangeService
+}
+
+// NewEmailChangeHandler creates a new EmailChangeHandler.
+func NewEmailChangeHandler(emailChangeService service.EmailChangeService) *EmailChangeHandler {
+	return &EmailChangeHandler{
+		emailChangeService: emailChangeService,
+	}
+}
+
+// RequestEmailChangeRequest defines the expected request body for starting an email change.
+type RequestEmailChangeRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// EmailChangeTokenRequest defines the expected request body for confirming or reverting an email change.
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestEmailChange starts an email change; the address is only swapped after confirmation.
+// POST /api/v1/users/:id/email-change
+func (h *EmailChangeHandler) RequestEmailChange(c *gin.Context) {
+	id, err := parseIDParam(c, "id", "user")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req RequestEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	err = h.emailChangeService.RequestEmailChange(c.Request.Context(), id, req.NewEmail, req.CurrentPassword)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation link sent to the new email address"})
+}
+
+// ConfirmEmailChange commits a pending email change.
+// POST /api/v1/email-changes/confirm
+func (h *EmailChangeHandler) ConfirmEmailChange(c *gin.Context) {
+	var req EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	user, err := h.emailChangeService.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address updated", "email": user.Email})
+}
+
+// RevertEmailChange cancels a pending email change or restores the previous address.
+// POST /api/v1/email-changes/revert
+func (h *EmailChangeHandler) RevertEmailChange(c *gin.Context) {
+	var req EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.emailChangeService.RevertEmailChange(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, service.ErrEmailTaken) {
+			err = apperror.Wrap(apperror.Conflict, "previous email is now used by another account", err)
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email change reverted"})
+}
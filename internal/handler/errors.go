@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+func init() {
+	// Report validation failures under the JSON names clients send instead of the Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the name of a struct field in JSON, or "" for fields that are not serialized
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// bindingError turns a request binding failure into a validation error listing the invalid fields.
+// Decoder and validator messages are not exposed; each field gets a stable code and a short message.
+func bindingError(err error) error {
+	domainErr := apperror.Wrap(apperror.Validation, "request body is invalid", err)
+
+	var fieldErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fieldErrs):
+		fields := make([]apperror.FieldError, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			fields = append(fields, validationFieldError(fe))
+		}
+		return domainErr.WithFields(fields...)
+	case errors.As(err, &typeErr):
+		return domainErr.WithFields(apperror.NewFieldError(jsonPath(typeErr.Field), "invalid_type", "must be "+jsonTypeName(typeErr.Type)))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		domainErr.Message = "request body is not valid JSON"
+	case errors.Is(err, io.EOF):
+		domainErr.Message = "request body is empty"
+	}
+	return domainErr
+}
+
+// validationFieldError maps a failed validator rule to a field error.
+// The field path drops the request struct name, e.g. "CreateUserRequest.email" becomes "email".
+func validationFieldError(fe validator.FieldError) apperror.FieldError {
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return apperror.NewFieldError(field, "required", "is required")
+	case "email":
+		return apperror.NewFieldError(field, "invalid_email", "must be a valid email address")
+	case "min":
+		return apperror.NewFieldError(field, "too_short", "must be at least %s"+lengthUnit(fe), fe.Param())
+	case "max":
+		return apperror.NewFieldError(field, "too_long", "must be at most %s"+lengthUnit(fe), fe.Param())
+	case "oneof":
+		return apperror.NewFieldError(field, "invalid_choice", "must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	}
+	return apperror.NewFieldError(field, fe.Tag(), "is invalid")
+}
+
+// lengthUnit names what min and max count for the kind of the field
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}
+
+// jsonTypeName describes a Go type in JSON terms, with its article
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// jsonPath rewrites the decoder's dotted field path to the validator's notation, e.g. "ids.0" to "ids[0]"
+func jsonPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// invalidParam reports an invalid path or query parameter; format describes what the value must be
+func invalidParam(name, code, format string, args ...any) error {
+	return apperror.Newf(apperror.Validation, "%s "+format, append([]any{name}, args...)...).
+		WithFields(apperror.NewFieldError(name, code, format, args...))
+}
+
+// parseIDParam reads a numeric path parameter; what names the resource in the error message
+func parseIDParam(c *gin.Context, name, what string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, apperror.New(apperror.Validation, "invalid "+what+" ID format").
+			WithFields(apperror.NewFieldError(name, "invalid_type", "must be an integer"))
+	}
+	return id, nil
+}
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// parsePage reads the limit (1-100, default 20) and offset query parameters of list endpoints
+func parsePage(c *gin.Context) (limit, offset int32, err error) {
+	limit = defaultListLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 || n > maxListLimit {
+			return 0, 0, invalidParam("limit", "out_of_range", "must be between 1 and %d", maxListLimit)
+		}
+		limit = int32(n)
+	}
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			return 0, 0, invalidParam("offset", "out_of_range", "must be a non-negative integer")
+		}
+		offset = int32(n)
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/health"
+)
+
+// HealthHandler serves the liveness, readiness and startup probes.
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Live reports that the process is running and able to serve HTTP; it checks no dependency, so an
+// outage of Postgres or Redis does not get the container restarted.
+// GET /healthz
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+// Ready reports whether the application can serve requests, with the status of each dependency.
+// Errors are logged, not returned, as the probes are public; the admin server shows them to operators.
+// GET /readyz
+func (h *HealthHandler) Ready(c *gin.Context) {
+	writeReport(c, h.registry.Ready(c.Request.Context()))
+}
+
+// Started reports whether the application finished starting, e.g. the migrations are applied.
+// GET /startupz
+func (h *HealthHandler) Started(c *gin.Context) {
+	writeReport(c, h.registry.Started(c.Request.Context()))
+}
+
+// writeReport answers 200 for a passing probe and 503 for a failing one
+func writeReport(c *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report.Public())
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/middleware"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// PrivacyHandler handles HTTP requests for GDPR data exports and erasure.
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+}
+
+// NewPrivacyHandler creates a new PrivacyHandler.
+func NewPrivacyHandler(privacyService service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// EraseUserRequest defines the expected request body for erasing the current user.
+type EraseUserRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// DataExportResponse defines the structure for data export responses.
+type DataExportResponse struct {
+	ID          int64  `json:"id"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+}
+
+// RequestExport queues an export of everything stored about the current user.
+// POST /api/v1/users/me/export
+func (h *PrivacyHandler) RequestExport(c *gin.Context) {
+	userID, err := middleware.UserID(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	export, err := h.privacyService.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrExportQueueFull) {
+			c.Header("Retry-After", "60")
+		}
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/users/me/exports/"+strconv.FormatInt(export.ID, 10))
+	c.JSON(http.StatusAccepted, newDataExportResponse(export, ""))
+}
+
+// GetExport reports the status of an export and links the archive once it is ready.
+// GET /api/v1/users/me/exports/:id
+func (h *PrivacyHandler) GetExport(c *gin.Context) {
+	userID, err := middleware.UserID(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	id, err := parseIDParam(c, "id", "export")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	export, downloadURL, err := h.privacyService.GetExport(c.Request.Context(), userID, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDataExportResponse(export, downloadURL))
+}
+
+// EraseUser deletes or anonymizes all personal data of the current user.
+// POST /api/v1/users/me/erase
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	userID, err := middleware.UserID(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var req EraseUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	if err := h.privacyService.EraseUser(c.Request.Context(), userID, req.CurrentPassword); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func newDataExportResponse(export sqlc.DataExport, downloadURL string) DataExportResponse {
+	resp := DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		Error:       export.Error.String,
+		DownloadURL: downloadURL,
+		CreatedAt:   export.CreatedAt.Format(time.RFC3339),
+	}
+	if export.CompletedAt.Valid {
+		resp.CompletedAt = export.CompletedAt.Time.Format(time.RFC3339)
+	}
+	if export.ExpiresAt.Valid {
+		resp.ExpiresAt = export.ExpiresAt.Time.Format(time.RFC3339)
+	}
+	return resp
+}
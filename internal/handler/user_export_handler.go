@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// UserExportHandler handles HTTP requests for bulk user exports.
+type UserExportHandler struct {
+	exportService service.UserExportService
+}
+
+// NewUserExportHandler creates a new UserExportHandler.
+func NewUserExportHandler(exportService service.UserExportService) *UserExportHandler {
+	return &UserExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportUsers streams every user matching the list filters as CSV, NDJSON or Parquet.
+// limit and offset are ignored. Password hashes are never part of the output.
+// GET /api/v1/users/export?format=csv|ndjson|parquet
+func (h *UserExportHandler) ExportUsers(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	contentType, err := h.exportService.ContentType(format)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := h.exportService.ExportUsers(c.Request.Context(), c.Writer, format, filter); err != nil {
+		// The status line is already sent, so the failure can only be logged
+		if !errors.Is(err, context.Canceled) {
+			ctx := c.Request.Context()
+			logging.FromContext(ctx).ErrorContext(ctx, "User export failed", slog.Any("error", err))
+		}
+		c.Abort()
+	}
+}
@@ -1,26 +1,40 @@
 package handler
 
 import (
-	"database/sql"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/middleware"
 	"github.com/yourusername/yourprojectname/internal/service"
+	"github.com/yourusername/yourprojectname/internal/util"
 )
 
 // UserHandler handles HTTP requests for user resources.
 type UserHandler struct {
-	userService service.UserService
+	userService     service.UserService
+	avatarService   service.AvatarService
+	bundle          *i18n.Bundle
+	batchGetMaxKeys int
 }
 
 // NewUserHandler creates a new UserHandler.
-func NewUserHandler(userService service.UserService) *UserHandler {
+// bundle defines the locales users may choose; batchGetMaxKeys caps the number of IDs plus emails
+// in a single batch lookup.
+func NewUserHandler(userService service.UserService, avatarService service.AvatarService, bundle *i18n.Bundle, batchGetMaxKeys int) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:     userService,
+		avatarService:   avatarService,
+		bundle:          bundle,
+		batchGetMaxKeys: batchGetMaxKeys,
 	}
 }
 
@@ -34,12 +48,14 @@ type CreateUserRequest struct {
 
 // UserResponse defines the structure for user responses, omitting sensitive data like password.
 type UserResponse struct {
-	ID        int64  `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int64             `json:"id"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Email     string            `json:"email"`
+	Avatar    map[string]string `json:"avatar,omitempty"` // signed, time-limited URL per variant
+	Locale    string            `json:"locale,omitempty"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
 }
 
 // CreateUser handles the creation of a new user.
@@ -47,7 +63,7 @@ type UserResponse struct {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload: " + err.Error()})
+		_ = c.Error(bindingError(err))
 		return
 	}
 
@@ -60,49 +76,427 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 	user, err := h.userService.CreateUser(c.Request.Context(), params)
 	if err != nil {
-		// TODO: Implement more specific error handling, e.g., for duplicate email.
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		_ = c.Error(err)
 		return
 	}
 
-	resp := UserResponse{
-		ID:        user.ID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+	resp, err := h.newUserResponse(c, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 	c.JSON(http.StatusCreated, resp)
 }
 
 // GetUserByID handles fetching a user by ID.
+// With ?as_of=<RFC 3339 timestamp> the user is reconstructed as it was at that time; this
+// is restricted to the user themselves and admins.
 // GET /api/v1/users/:id
 func (h *UserHandler) GetUserByID(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	if c.Query("as_of") != "" {
+		h.getUserAsOf(c)
+		return
+	}
+
+	id, err := parseIDParam(c, "id", "user")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		_ = c.Error(err)
 		return
 	}
 
 	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		_ = c.Error(err)
+		return
+	}
+
+	resp, err := h.newUserResponse(c, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *UserHandler) getUserAsOf(c *gin.Context) {
+	asOf, err := time.Parse(time.RFC3339, c.Query("as_of"))
+	if err != nil {
+		_ = c.Error(invalidParam("as_of", "invalid_format", "must be an RFC 3339 timestamp"))
+		return
+	}
+	id, ok := h.authorizeSelfOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserAsOf(c.Request.Context(), id, asOf)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp, err := h.newUserResponse(c, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UserVersionResponse defines the structure of a prior version of a user.
+// The version was current from ValidFrom until ValidTo, when it was updated or deleted.
+type UserVersionResponse struct {
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	AvatarKey *string   `json:"avatar_key"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   time.Time `json:"valid_to"`
+	Operation string    `json:"operation"`
+}
+
+// UserHistoryResponse lists the prior versions of a user, newest first.
+type UserHistoryResponse struct {
+	UserID   int64                 `json:"user_id"`
+	Versions []UserVersionResponse `json:"versions"`
+}
+
+// GetUserHistory handles listing every prior version of a user, including deleted users.
+// Restricted to the user themselves and admins.
+// GET /api/v1/users/:id/history
+func (h *UserHandler) GetUserHistory(c *gin.Context) {
+	id, ok := h.authorizeSelfOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.userService.ListUserHistory(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := UserHistoryResponse{UserID: id, Versions: make([]UserVersionResponse, 0, len(history))}
+	for _, v := range history {
+		version := UserVersionResponse{
+			FirstName: v.FirstName,
+			LastName:  v.LastName,
+			Email:     v.Email,
+			IsAdmin:   v.IsAdmin,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+			ValidFrom: v.ValidFrom,
+			ValidTo:   v.ValidTo,
+			Operation: v.Operation,
+		}
+		if v.AvatarKey.Valid {
+			version.AvatarKey = &v.AvatarKey.String
+		}
+		resp.Versions = append(resp.Versions, version)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateUserRequest defines the expected request body for updating a user.
+// Omitted fields are left unchanged; the email is changed through the email change flow.
+// Locale selects the language of error messages and must be one of the supported locales.
+type UpdateUserRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=1"`
+	Password  *string `json:"password" binding:"omitempty,min=8"`
+	Locale    *string `json:"locale"`
+}
+
+// UpdateUser handles updating the authenticated user, or any user for admins.
+// PUT /api/v1/users/:id
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, ok := h.authorizeSelfOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+
+	params := sqlc.UpdateUserParams{ID: id}
+	if req.FirstName != nil {
+		params.FirstName = pgtype.Text{String: *req.FirstName, Valid: true}
+	}
+	if req.LastName != nil {
+		params.LastName = pgtype.Text{String: *req.LastName, Valid: true}
+	}
+	if req.Password != nil {
+		params.HashedPassword = pgtype.Text{String: *req.Password, Valid: true}
+	}
+	if req.Locale != nil {
+		locale, ok := h.bundle.Supported(*req.Locale)
+		if !ok {
+			_ = c.Error(apperror.New(apperror.Validation, "request body is invalid").WithFields(
+				apperror.NewFieldError("locale", "unsupported_locale", "must be one of: %s", strings.Join(h.bundle.Locales(), ", "))))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		params.Locale = pgtype.Text{String: locale, Valid: true}
+	}
+
+	user, err := h.userService.UpdateUser(c.Request.Context(), params)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp, err := h.newUserResponse(c, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteUser handles deleting the authenticated user, or any user for admins.
+// DELETE /api/v1/users/:id
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := h.authorizeSelfOrAdmin(c)
+	if !ok {
 		return
 	}
 
-	resp := UserResponse{
+	if err := h.userService.DeleteUser(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// authorizeSelfOrAdmin parses the :id parameter and lets the request through if it names the
+// authenticated user or the caller is an admin. Otherwise it attaches the error for ErrorHandler.
+func (h *UserHandler) authorizeSelfOrAdmin(c *gin.Context) (int64, bool) {
+	id, err := parseIDParam(c, "id", "user")
+	if err != nil {
+		_ = c.Error(err)
+		return 0, false
+	}
+	callerID, err := middleware.UserID(c)
+	if err != nil {
+		_ = c.Error(err)
+		return 0, false
+	}
+	if callerID == id {
+		return id, true
+	}
+
+	caller, err := h.userService.GetUserByID(c.Request.Context(), callerID)
+	if err != nil && !errors.Is(err, apperror.NotFound) {
+		_ = c.Error(err)
+		return 0, false
+	}
+	if err != nil || !caller.IsAdmin {
+		_ = c.Error(apperror.New(apperror.Forbidden, "not allowed to access this user"))
+		return 0, false
+	}
+	return id, true
+}
+
+// ListUsersResponse defines the structure for a page of users.
+type ListUsersResponse struct {
+	Users  []UserResponse `json:"users"`
+	Limit  int32          `json:"limit"`
+	Offset int32          `json:"offset"`
+}
+
+// ListUsers handles listing users with optional filters, see parseUserFilter.
+// GET /api/v1/users
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	params, err := parseUserFilter(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	users, err := h.userService.ListUsers(c.Request.Context(), params)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := ListUsersResponse{Users: make([]UserResponse, 0, len(users)), Limit: params.RowLimit, Offset: params.RowOffset}
+	for _, user := range users {
+		userResp, err := h.newUserResponse(c, user)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		resp.Users = append(resp.Users, userResp)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BatchGetUsersRequest defines the expected request body for a batch lookup.
+type BatchGetUsersRequest struct {
+	IDs    []int64  `json:"ids"`
+	Emails []string `json:"emails"`
+}
+
+// BatchGetUsersResponse lists the found users in request order, IDs before emails,
+// and the keys that matched no user.
+type BatchGetUsersResponse struct {
+	Users         []UserResponse `json:"users"`
+	MissingIDs    []int64        `json:"missing_ids"`
+	MissingEmails []string       `json:"missing_emails"`
+}
+
+// BatchGetUsers handles looking up many users by ID and/or email in one request.
+// POST /api/v1/users:batchGet
+func (h *UserHandler) BatchGetUsers(c *gin.Context) {
+	var req BatchGetUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(bindingError(err))
+		return
+	}
+	if len(req.IDs)+len(req.Emails) == 0 {
+		_ = c.Error(apperror.New(apperror.Validation, "at least one id or email is required"))
+		return
+	}
+	if len(req.IDs)+len(req.Emails) > h.batchGetMaxKeys {
+		_ = c.Error(apperror.Newf(apperror.Validation, "at most %d ids and emails may be requested at once", h.batchGetMaxKeys))
+		return
+	}
+
+	result, err := h.userService.BatchGetUsers(c.Request.Context(), req.IDs, req.Emails)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := BatchGetUsersResponse{
+		Users:         make([]UserResponse, 0, len(result.Users)),
+		MissingIDs:    result.MissingIDs,
+		MissingEmails: result.MissingEmails,
+	}
+	for _, user := range result.Users {
+		userResp, err := h.newUserResponse(c, user)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		resp.Users = append(resp.Users, userResp)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// UploadAvatar handles replacing the avatar of the authenticated user, or of any user for admins,
+// with a multipart upload in the "avatar" field.
+// PUT /api/v1/users/:id/avatar
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	id, ok := h.authorizeSelfOrAdmin(c)
+	if !ok {
+		return
+	}
+
+	maxBytes := h.avatarService.MaxBytes()
+	// Leave room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+64<<10)
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			_ = c.Error(util.ErrImageTooLarge)
+			return
+		}
+		_ = c.Error(apperror.Wrap(apperror.Validation, `missing avatar file in multipart field "avatar"`, err))
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		_ = c.Error(util.ErrImageTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.Validation, "unable to read avatar file", err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		_ = c.Error(apperror.Wrap(apperror.Validation, "unable to read avatar file", err))
+		return
+	}
+
+	user, err := h.avatarService.UploadAvatar(c.Request.Context(), id, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp, err := h.newUserResponse(c, user)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// newUserResponse maps a user to its public representation, including signed avatar URLs.
+func (h *UserHandler) newUserResponse(c *gin.Context, user sqlc.User) (UserResponse, error) {
+	avatar, err := h.avatarService.AvatarURLs(c.Request.Context(), user)
+	if err != nil {
+		return UserResponse{}, err
+	}
+	return UserResponse{
 		ID:        user.ID,
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Email:     user.Email,
+		Avatar:    avatar,
+		Locale:    user.Locale.String,
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// parseUserFilter reads the filter grammar shared by the list and export endpoints:
+// email (exact match), q (case-insensitive substring of name or email), is_admin (bool),
+// created_after and created_before (RFC 3339), limit (1-100, default 20) and offset.
+func parseUserFilter(c *gin.Context) (sqlc.ListUsersParams, error) {
+	params := sqlc.ListUsersParams{}
+
+	if v := c.Query("email"); v != "" {
+		params.Email = pgtype.Text{String: v, Valid: true}
 	}
-	c.JSON(http.StatusOK, resp)
+	if v := c.Query("q"); v != "" {
+		params.Search = pgtype.Text{String: v, Valid: true}
+	}
+	if v := c.Query("is_admin"); v != "" {
+		isAdmin, err := strconv.ParseBool(v)
+		if err != nil {
+			return params, invalidParam("is_admin", "invalid_type", "must be true or false")
+		}
+		params.IsAdmin = pgtype.Bool{Bool: isAdmin, Valid: true}
+	}
+	for name, target := range map[string]*pgtype.Timestamptz{
+		"created_after":  &params.CreatedAfter,
+		"created_before": &params.CreatedBefore,
+	} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return params, invalidParam(name, "invalid_format", "must be an RFC 3339 timestamp")
+			}
+			*target = pgtype.Timestamptz{Time: t, Valid: true}
+		}
+	}
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		return params, err
+	}
+	params.RowLimit, params.RowOffset = limit, offset
+	return params, nil
 }
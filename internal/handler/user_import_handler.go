@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// UserImportHandler handles HTTP requests for bulk user imports.
+type UserImportHandler struct {
+	importService service.UserImportService
+	maxBytes      int64
+	batchSize     int
+}
+
+// NewUserImportHandler creates a new UserImportHandler.
+func NewUserImportHandler(importService service.UserImportService, maxBytes int64, batchSize int) *UserImportHandler {
+	return &UserImportHandler{
+		importService: importService,
+		maxBytes:      maxBytes,
+		batchSize:     batchSize,
+	}
+}
+
+// ImportUsers streams a CSV or NDJSON request body into the users table and returns a per-row report.
+// The format is taken from ?format=csv|ndjson or the Content-Type (text/csv, application/x-ndjson).
+// POST /api/v1/admin/users/import?format=csv&dry_run=true
+func (h *UserImportHandler) ImportUsers(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/jsonl":
+			format = "ndjson"
+		}
+	}
+
+	dryRun := false
+	if v := c.Query("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			_ = c.Error(invalidParam("dry_run", "invalid_type", "must be true or false"))
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes)
+	report, err := h.importService.ImportUsers(c.Request.Context(), body, service.ImportOptions{
+		Format:    format,
+		DryRun:    dryRun,
+		BatchSize: h.batchSize,
+	})
+	if err != nil {
+		// Batches before the failure stay committed, so the partial report is attached to every error
+		var maxBytesErr *http.MaxBytesError
+		domainErr, ok := apperror.As(err)
+		switch {
+		case errors.As(err, &maxBytesErr):
+			domainErr = apperror.Wrap(apperror.TooLarge, "import exceeds the maximum upload size", err)
+		case !ok:
+			domainErr = apperror.Wrap(apperror.Internal, "import aborted", err)
+		}
+		_ = c.Error(domainErr.WithExtension("report", report))
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
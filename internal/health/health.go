@@ -0,0 +1,171 @@
+// Package health tracks whether the application is started, ready to serve and healthy, for the
+// liveness, readiness and startup probes of container orchestrators.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/yourprojectname/internal/logging"
+)
+
+// Statuses reported for the application and each of its components
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// HealthChecker checks whether a dependency is usable. CheckHealth must return once ctx is done.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to HealthChecker
+type CheckerFunc func(ctx context.Context) error
+
+// CheckHealth calls f
+func (f CheckerFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ComponentStatus is the result of one check. Error may name hosts, users and driver internals,
+// so it is only shown to operators, see Report.Public.
+type ComponentStatus struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Report is the result of a probe: ok only if every component is ok
+type Report struct {
+	Status     string                     `json:"status"`
+	Reason     string                     `json:"reason,omitempty"` // why the probe fails regardless of the components
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// OK reports whether the probe passed
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Public returns a copy of r without the errors of the components, for the unauthenticated probes
+func (r Report) Public() Report {
+	if r.Components == nil {
+		return r
+	}
+	components := make(map[string]ComponentStatus, len(r.Components))
+	for name, status := range r.Components {
+		status.Error = ""
+		components[name] = status
+	}
+	r.Components = components
+	return r
+}
+
+type namedChecker struct {
+	name    string
+	checker HealthChecker
+}
+
+// Registry holds the readiness and startup checks and the lifecycle state of the application.
+// The application is ready once it has started, while every readiness check passes and until shutdown begins.
+type Registry struct {
+	timeout time.Duration
+
+	mu        sync.Mutex
+	readiness []namedChecker
+	startup   []namedChecker
+
+	started      atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates a registry running each check with the given timeout
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// AddReadinessCheck registers a dependency the application cannot serve requests without, e.g. the database
+func (r *Registry) AddReadinessCheck(name string, checker HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, namedChecker{name, checker})
+}
+
+// AddStartupCheck registers a condition that must hold once before the application counts as started,
+// e.g. that migrations are applied. Startup checks are no longer run after they all passed.
+func (r *Registry) AddStartupCheck(name string, checker HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.startup = append(r.startup, namedChecker{name, checker})
+}
+
+// Started runs the startup checks until they all pass once
+func (r *Registry) Started(ctx context.Context) Report {
+	if r.started.Load() {
+		return Report{Status: StatusOK}
+	}
+	report := r.run(ctx, r.checks(&r.startup))
+	if report.OK() {
+		r.started.Store(true)
+	}
+	return report
+}
+
+// Ready runs the readiness checks. It fails without running them before startup completed and after
+// shutdown began, so load balancers stop sending requests before the server stops accepting them.
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusFailing, Reason: "shutting down"}
+	}
+	if !r.Started(ctx).OK() {
+		return Report{Status: StatusFailing, Reason: "starting"}
+	}
+	return r.run(ctx, r.checks(&r.readiness))
+}
+
+// ShutDown makes readiness fail from now on
+func (r *Registry) ShutDown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) checks(list *[]namedChecker) []namedChecker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]namedChecker(nil), *list...)
+}
+
+// run runs checks concurrently, each with the registry's timeout. Failures are logged with their error.
+func (r *Registry) run(ctx context.Context, checks []namedChecker) Report {
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedChecker) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+			start := time.Now()
+			err := c.checker.CheckHealth(checkCtx)
+			status := ComponentStatus{Status: StatusOK, DurationMS: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				status.Status = StatusFailing
+				status.Error = err.Error()
+				logging.FromContext(ctx).WarnContext(ctx, "Health check failed", slog.String("component", c.name), slog.Any("error", err))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[c.name] = status
+			if err != nil {
+				report.Status = StatusFailing
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("dial tcp 10.0.0.5:5432: connect: connection refused")
+
+// checker returns a check reporting the current value of err and counting its calls
+func checker(err *atomic.Pointer[error], calls *atomic.Int32) HealthChecker {
+	return CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		if e := err.Load(); e != nil {
+			return *e
+		}
+		return nil
+	})
+}
+
+func TestRegistry_States(t *testing.T) {
+	tests := []struct {
+		name         string
+		startupErr   error
+		readinessErr error
+		shutDown     bool
+		wantStarted  bool
+		wantReady    bool
+		wantReason   string
+		wantFailing  string // component reported as failing by Ready
+	}{
+		{name: "started and ready", wantStarted: true, wantReady: true},
+		{name: "startup check failing", startupErr: errDown, wantReason: "starting"},
+		{name: "dependency down", readinessErr: errDown, wantStarted: true, wantFailing: "database"},
+		{name: "shutting down", shutDown: true, wantStarted: true, wantReason: "shutting down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var startupErr, readinessErr atomic.Pointer[error]
+			var startupCalls, readinessCalls atomic.Int32
+			if tt.startupErr != nil {
+				startupErr.Store(&tt.startupErr)
+			}
+			if tt.readinessErr != nil {
+				readinessErr.Store(&tt.readinessErr)
+			}
+			r := NewRegistry(time.Second)
+			r.AddStartupCheck("migrations", checker(&startupErr, &startupCalls))
+			r.AddReadinessCheck("database", checker(&readinessErr, &readinessCalls))
+			if tt.shutDown {
+				r.ShutDown()
+			}
+
+			ctx := context.Background()
+			if got := r.Started(ctx).OK(); got != tt.wantStarted {
+				t.Errorf("Started().OK() = %v, want %v", got, tt.wantStarted)
+			}
+			ready := r.Ready(ctx)
+			if ready.OK() != tt.wantReady || ready.Reason != tt.wantReason {
+				t.Errorf("Ready() = %+v, want ok %v and reason %q", ready, tt.wantReady, tt.wantReason)
+			}
+			if tt.wantFailing != "" && ready.Components[tt.wantFailing].Status != StatusFailing {
+				t.Errorf("Ready().Components = %+v, want %s failing", ready.Components, tt.wantFailing)
+			}
+			// Readiness checks are not run before startup completed or after shutdown began
+			if tt.wantReason != "" && readinessCalls.Load() != 0 {
+				t.Errorf("readiness checks ran %d times, want none", readinessCalls.Load())
+			}
+		})
+	}
+}
+
+func TestRegistry_StartupChecksStopOncePassed(t *testing.T) {
+	var err atomic.Pointer[error]
+	var calls atomic.Int32
+	down := error(errDown)
+	err.Store(&down)
+	r := NewRegistry(time.Second)
+	r.AddStartupCheck("migrations", checker(&err, &calls))
+	ctx := context.Background()
+
+	if r.Started(ctx).OK() {
+		t.Fatal("Started passed with a failing check")
+	}
+	err.Store(nil)
+	if !r.Started(ctx).OK() {
+		t.Fatal("Started failed after the check recovered")
+	}
+	// A later failure no longer matters: the application started once
+	err.Store(&down)
+	if !r.Started(ctx).OK() {
+		t.Error("Started failed again after it passed")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("startup check ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestRegistry_CheckTimeout(t *testing.T) {
+	r := NewRegistry(10 * time.Millisecond)
+	r.AddReadinessCheck("redis", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	report := r.Ready(context.Background())
+	if report.OK() || report.Components["redis"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Ready() with a hanging check = %+v, want redis failing with a deadline error", report)
+	}
+}
+
+func TestReport_Public(t *testing.T) {
+	r := NewRegistry(time.Second)
+	r.AddReadinessCheck("database", CheckerFunc(func(context.Context) error { return errDown }))
+	r.AddReadinessCheck("redis", CheckerFunc(func(context.Context) error { return nil }))
+
+	report := r.Ready(context.Background())
+	public := report.Public()
+	for name, status := range public.Components {
+		if status.Error != "" {
+			t.Errorf("public component %s shows error %q", name, status.Error)
+		}
+	}
+	if public.Components["database"].Status != StatusFailing || public.Status != StatusFailing {
+		t.Errorf("Public() = %+v, want the statuses kept", public)
+	}
+	// The report of operators keeps the error
+	if report.Components["database"].Error != errDown.Error() {
+		t.Errorf("Public() changed the original report: %+v", report.Components["database"])
+	}
+}
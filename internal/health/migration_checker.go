@@ -0,0 +1,36 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryRower is the part of pgxpool.Pool the migration check needs
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// MigrationChecker passes once the golang-migrate version table reports at least version and is not dirty
+func MigrationChecker(db queryRower, version uint) HealthChecker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var current int64
+		var dirty bool
+		err := db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows), errors.As(err, &pgErr) && pgErr.Code == "42P01": // undefined_table
+			return fmt.Errorf("no migrations applied, want version %d", version)
+		case err != nil:
+			return fmt.Errorf("unable to read the migration version: %w", err)
+		case dirty:
+			return fmt.Errorf("migration %d failed and left the schema dirty", current)
+		case current < int64(version):
+			return fmt.Errorf("schema at version %d, want %d", current, version)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,152 @@
+// Package i18n translates client-facing messages.
+// Catalogs are JSON files embedded in the binary, one per locale, mapping an English message or
+// format string to its translation. English is the source language: en.json lists every message,
+// and any message missing from a catalog falls back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the source language of all messages and the fallback for unsupported locales.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var catalogFiles embed.FS
+
+// Bundle holds the message catalogs of every supported locale.
+type Bundle struct {
+	catalogs map[string]map[string]string
+	locales  []string // DefaultLocale first
+	matcher  language.Matcher
+}
+
+// Load parses the embedded catalogs. It fails if a catalog is malformed or incomplete, i.e. it
+// lacks a message of en.json, has a message en.json does not know, or changes the format verbs
+// of a message, so a missing translation stops the server at startup instead of reaching users.
+func Load() (*Bundle, error) {
+	entries, err := catalogFiles.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{catalogs: make(map[string]map[string]string, len(entries))}
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := catalogFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", entry.Name(), err)
+		}
+		b.catalogs[locale] = catalog
+		b.locales = append(b.locales, locale)
+	}
+	if _, ok := b.catalogs[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("catalog %s.json is missing", DefaultLocale)
+	}
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+
+	// The matcher prefers its first tag when nothing matches, so the default goes first
+	sort.Slice(b.locales, func(i, j int) bool {
+		return b.locales[i] == DefaultLocale || (b.locales[j] != DefaultLocale && b.locales[i] < b.locales[j])
+	})
+	tags := make([]language.Tag, len(b.locales))
+	for i, locale := range b.locales {
+		tags[i] = language.Make(locale)
+	}
+	b.matcher = language.NewMatcher(tags)
+	return b, nil
+}
+
+// formatVerb matches the fmt verbs of a format string
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+// check reports every message that is missing, unknown or has different format verbs than English
+func (b *Bundle) check() error {
+	source := b.catalogs[DefaultLocale]
+	var problems []error
+	for _, locale := range b.locales {
+		if locale == DefaultLocale {
+			continue
+		}
+		catalog := b.catalogs[locale]
+		for msg := range source {
+			translation, ok := catalog[msg]
+			switch {
+			case !ok || translation == "":
+				problems = append(problems, fmt.Errorf("%s: missing translation of %q", locale, msg))
+			case !slices.Equal(formatVerb.FindAllString(msg, -1), formatVerb.FindAllString(translation, -1)):
+				problems = append(problems, fmt.Errorf("%s: translation of %q has different format verbs", locale, msg))
+			}
+		}
+		for msg := range catalog {
+			if _, ok := source[msg]; !ok {
+				problems = append(problems, fmt.Errorf("%s: %q is not a message of %s.json", locale, msg, DefaultLocale))
+			}
+		}
+	}
+	// Sorted so the report is stable between runs
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Error() < problems[j].Error() })
+	return errors.Join(problems...)
+}
+
+// Locales returns the supported locales, DefaultLocale first.
+func (b *Bundle) Locales() []string {
+	return slices.Clone(b.locales)
+}
+
+// Supported returns the supported locale named by tag, ignoring case, and whether there is one.
+func (b *Bundle) Supported(tag string) (string, bool) {
+	for _, locale := range b.locales {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Match returns the best supported locale for the given preferences, most important first.
+// Each preference is a locale or an Accept-Language header value; empty ones are skipped.
+// Without a usable preference it returns DefaultLocale.
+func (b *Bundle) Match(preferences ...string) string {
+	for _, pref := range preferences {
+		if pref == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(pref)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		_, index, confidence := b.matcher.Match(tags...)
+		if confidence != language.No {
+			return b.locales[index]
+		}
+	}
+	return DefaultLocale
+}
+
+// Translator returns a function translating messages into locale, falling back to English.
+func (b *Bundle) Translator(locale string) func(string) string {
+	catalog := b.catalogs[locale]
+	return func(msg string) string {
+		if translation := catalog[msg]; translation != "" {
+			return translation
+		}
+		return msg
+	}
+}
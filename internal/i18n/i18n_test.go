@@ -0,0 +1,212 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoad_CatalogsAreComplete(t *testing.T) {
+	b, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, locale := range []string{"de", "fr"} {
+		if _, ok := b.Supported(locale); !ok {
+			t.Errorf("locale %s is not supported", locale)
+		}
+	}
+}
+
+func TestCheck_ReportsMissingAndUnknownMessages(t *testing.T) {
+	b := &Bundle{
+		catalogs: map[string]map[string]string{
+			"en": {"user not found": "user not found", "%s must be an integer": "%s must be an integer"},
+			"de": {"user not found": "Benutzer nicht gefunden", "stale message": "veraltet"},
+			"fr": {"user not found": "utilisateur introuvable", "%s must be an integer": "%d doit être un entier"},
+		},
+		locales: []string{"de", "en", "fr"},
+	}
+
+	err := b.check()
+	if err == nil {
+		t.Fatal("check accepted incomplete catalogs")
+	}
+	for _, want := range []string{
+		`de: missing translation of "%s must be an integer"`,
+		`de: "stale message" is not a message of en.json`,
+		`fr: translation of "%s must be an integer" has different format verbs`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("check error %q does not report %q", err, want)
+		}
+	}
+}
+
+// messageArgs gives the position of the message or format argument of the functions creating client-facing errors
+var messageArgs = map[string]int{
+	"apperror.New":           1,
+	"apperror.Newf":          1,
+	"apperror.Wrap":          1,
+	"apperror.Wrapf":         2,
+	"apperror.NewFieldError": 2,
+	"invalidParam":           2,
+	"translateError":         1,
+	"parseIDParam":           2,
+}
+
+// messageTables are maps whose values are client-facing messages
+var messageTables = map[string]bool{
+	"problemKinds":       true,
+	"constraintMessages": true,
+}
+
+func TestMessagesUsedInCodeAreInCatalog(t *testing.T) {
+	b, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	source := b.catalogs[DefaultLocale]
+
+	used := usedMessages(t, filepath.Join("..", ".."))
+	// Guards against the scan silently finding nothing after a refactoring
+	if len(used) < 50 {
+		t.Fatalf("found only %d messages in the code, the scan is broken", len(used))
+	}
+	var missing []string
+	for msg, pos := range used {
+		if _, ok := source[msg]; !ok {
+			missing = append(missing, pos+": "+strconv.Quote(msg))
+		}
+	}
+	sort.Strings(missing)
+	for _, m := range missing {
+		t.Errorf("%s is not in %s.json", m, DefaultLocale)
+	}
+}
+
+// usedMessages returns the literal messages of the Go files below root, mapped to where they are used.
+// Messages built from variables cannot be checked and are skipped.
+func usedMessages(t *testing.T, root string) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	used := make(map[string]string)
+	add := func(node ast.Node, msgs ...string) {
+		for _, msg := range msgs {
+			used[msg] = fset.Position(node.Pos()).String()
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				name := calleeName(n.Fun)
+				i, ok := messageArgs[name]
+				if !ok || i >= len(n.Args) {
+					return true
+				}
+				msg, ok := stringValue(n.Args[i])
+				if !ok {
+					return true
+				}
+				switch name {
+				case "invalidParam":
+					// The detail names the parameter, the field error does not
+					add(n, msg, "%s "+msg)
+				case "translateError":
+					add(n, msg+" not found", msg+" already exists",
+						msg+" references a record that does not exist or is still referenced", "invalid "+msg+": %s")
+				case "parseIDParam":
+					add(n, "invalid "+msg+" ID format")
+				default:
+					add(n, msg)
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) != 1 || !messageTables[n.Names[0].Name] || len(n.Values) != 1 {
+					return true
+				}
+				table, ok := n.Values[0].(*ast.CompositeLit)
+				if !ok {
+					return true
+				}
+				for _, elt := range table.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						ast.Inspect(kv.Value, func(v ast.Node) bool {
+							if msg, ok := stringValue(v); ok {
+								add(v, msg)
+							}
+							return true
+						})
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scanning the code: %v", err)
+	}
+	return used
+}
+
+// calleeName returns the name of a called function, qualified by its package outside the package
+func calleeName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok {
+			return pkg.Name + "." + f.Sel.Name
+		}
+	}
+	return ""
+}
+
+// stringValue returns the value of a string literal or a concatenation of string literals
+func stringValue(expr ast.Node) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPServer returns a hook that listens on srv.Addr when started, so a taken port fails the start,
+// and serves in the background. Stopping closes the listener and waits for in-flight requests
+// until ctx is done. fail receives the error if serving stops unexpectedly.
+func HTTPServer(name string, srv *http.Server, fail func(error)) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			var lc net.ListenConfig
+			ln, err := lc.Listen(ctx, "tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			slog.Info(name+" listening", slog.String("addr", ln.Addr().String()))
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					fail(fmt.Errorf("%s failed: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		OnStop: srv.Shutdown,
+	}
+}
+
+// Worker returns a hook that runs fn in a goroutine once started. Stopping cancels the context
+// passed to fn and waits until fn returns or ctx is done.
+func Worker(name string, fn func(ctx context.Context)) Hook {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			// The start context only covers starting; workers run until stopped
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				fn(workerCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Drain returns a hook to append last. Stopping it calls markUnready, so readiness probes fail,
+// and waits for period while the servers appended before it still accept requests, giving load
+// balancers time to stop routing here. It is only stopped if every earlier hook started.
+func Drain(period time.Duration, markUnready func()) Hook {
+	return Hook{
+		Name: "drain",
+		// Non-nil, so the hook is not active before Start reached it
+		OnStart: func(context.Context) error { return nil },
+		OnStop: func(ctx context.Context) error {
+			markUnready()
+			if period <= 0 {
+				return nil
+			}
+			slog.Info("Draining before closing listeners", slog.Duration("period", period))
+			timer := time.NewTimer(period)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
@@ -0,0 +1,111 @@
+// Package lifecycle starts the components of the application in order and stops them in reverse order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Hook is a component with a start and a stop step; either may be nil.
+// A hook without OnStart stands for a resource that is already open, such as a connection pool,
+// and is stopped even if a later hook fails to start.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Manager runs the hooks appended to it. The zero value is not usable; call New.
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	active  []bool // whether the hook at the same index needs stopping
+	next    int    // index of the first hook Start has not run yet
+	stopped bool
+	failed  chan error
+}
+
+// New creates a Manager without hooks.
+func New() *Manager {
+	return &Manager{failed: make(chan error, 1)}
+}
+
+// Append registers a hook after the existing ones.
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+	m.active = append(m.active, hook.OnStart == nil)
+}
+
+// Start runs the OnStart of every hook appended since the last call, in order, and stops at the first error.
+// The caller still has to call Stop to release the hooks started so far.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return errors.New("lifecycle: start after stop")
+	}
+	for ; m.next < len(m.hooks); m.next++ {
+		hook := m.hooks[m.next]
+		if hook.OnStart == nil {
+			continue
+		}
+		if err := hook.OnStart(ctx); err != nil {
+			return fmt.Errorf("failed to start %s: %w", hook.Name, err)
+		}
+		m.active[m.next] = true
+	}
+	return nil
+}
+
+// Stop runs the OnStop of every active hook in reverse order. A failing or late hook does not keep
+// the earlier ones from stopping; all errors are returned joined. Calls after the first do nothing.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return nil
+	}
+	m.stopped = true
+
+	var errs []error
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		hook := m.hooks[i]
+		if !m.active[i] || hook.OnStop == nil {
+			continue
+		}
+		start := time.Now()
+		if err := hook.OnStop(ctx); err != nil {
+			slog.Error("Failed to stop "+hook.Name, slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+			continue
+		}
+		slog.Info("Stopped "+hook.Name, slog.Duration("duration", time.Since(start)))
+	}
+	return errors.Join(errs...)
+}
+
+// Fail reports that a running component failed, such as a server whose listener broke.
+// Wait returns the first reported error; later ones are dropped.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failed <- err:
+	default:
+	}
+}
+
+// Wait blocks until ctx is done, typically on a shutdown signal, and returns nil,
+// or until a component calls Fail, and returns its error.
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-m.failed:
+		return err
+	}
+}
@@ -0,0 +1,228 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder collects the start and stop steps of hooks in the order they ran
+type recorder struct {
+	events []string
+}
+
+// hook returns a hook recording its steps; failStart and failStop make the step return an error,
+// and a hook without start stands for an already open resource
+func (r *recorder) hook(name string, noStart, failStart, failStop bool) Hook {
+	h := Hook{
+		Name: name,
+		OnStop: func(context.Context) error {
+			r.events = append(r.events, "stop "+name)
+			if failStop {
+				return errors.New(name + " stuck")
+			}
+			return nil
+		},
+	}
+	if !noStart {
+		h.OnStart = func(context.Context) error {
+			r.events = append(r.events, "start "+name)
+			if failStart {
+				return errors.New(name + " unavailable")
+			}
+			return nil
+		}
+	}
+	return h
+}
+
+func TestManager_StartStopOrder(t *testing.T) {
+	type hookSpec struct {
+		name                         string
+		noStart, failStart, failStop bool
+	}
+	tests := []struct {
+		name         string
+		hooks        []hookSpec
+		wantStartErr string
+		wantStopErr  []string
+		wantEvents   []string
+	}{
+		{
+			name:       "stops in reverse order",
+			hooks:      []hookSpec{{name: "db"}, {name: "cache"}, {name: "server"}},
+			wantEvents: []string{"start db", "start cache", "start server", "stop server", "stop cache", "stop db"},
+		},
+		{
+			name:         "rolls back the hooks started before a failure",
+			hooks:        []hookSpec{{name: "db"}, {name: "cache", failStart: true}, {name: "server"}},
+			wantStartErr: "failed to start cache: cache unavailable",
+			wantEvents:   []string{"start db", "start cache", "stop db"},
+		},
+		{
+			name:         "stops open resources after a failure",
+			hooks:        []hookSpec{{name: "pool", noStart: true}, {name: "server", failStart: true}, {name: "redis", noStart: true}},
+			wantStartErr: "failed to start server: server unavailable",
+			wantEvents:   []string{"start server", "stop redis", "stop pool"},
+		},
+		{
+			name:        "a failing stop does not keep earlier hooks running",
+			hooks:       []hookSpec{{name: "db"}, {name: "worker", failStop: true}, {name: "server", failStop: true}},
+			wantStopErr: []string{"failed to stop server: server stuck", "failed to stop worker: worker stuck"},
+			wantEvents:  []string{"start db", "start worker", "start server", "stop server", "stop worker", "stop db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			m := New()
+			for _, h := range tt.hooks {
+				m.Append(r.hook(h.name, h.noStart, h.failStart, h.failStop))
+			}
+
+			err := m.Start(context.Background())
+			if tt.wantStartErr == "" && err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			if tt.wantStartErr != "" && (err == nil || err.Error() != tt.wantStartErr) {
+				t.Errorf("Start = %v, want %q", err, tt.wantStartErr)
+			}
+
+			err = m.Stop(context.Background())
+			for _, want := range tt.wantStopErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("Stop = %v, want an error containing %q", err, want)
+				}
+			}
+			if len(tt.wantStopErr) == 0 && err != nil {
+				t.Errorf("Stop: %v", err)
+			}
+			if !slices.Equal(r.events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", r.events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestManager_StartRunsOnlyNewHooks(t *testing.T) {
+	r := &recorder{}
+	m := New()
+	m.Append(r.hook("db", false, false, false))
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("first Start: %v", err)
+	}
+	m.Append(r.hook("server", false, false, false))
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("second Start: %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	// Stopping twice does nothing, and nothing starts after a stop
+	if err := m.Stop(context.Background()); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+	if err := m.Start(context.Background()); err == nil {
+		t.Error("Start after Stop succeeded")
+	}
+
+	want := []string{"start db", "start server", "stop server", "stop db"}
+	if !slices.Equal(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestManager_Wait(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    []error
+		cancel  bool
+		wantErr error
+	}{
+		{name: "shutdown signal", cancel: true},
+		{name: "failed component", fail: []error{errServe}, wantErr: errServe},
+		{name: "first failure wins", fail: []error{errServe, errors.New("admin failed")}, wantErr: errServe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			for _, err := range tt.fail {
+				m.Fail(err)
+			}
+			if tt.cancel {
+				cancel()
+			}
+			if err := m.Wait(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Wait = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+var errServe = errors.New("server failed")
+
+func TestWorker_StopCancelsAndWaits(t *testing.T) {
+	returned := make(chan struct{})
+	hook := Worker("job", func(ctx context.Context) {
+		<-ctx.Done()
+		close(returned)
+	})
+	if err := hook.OnStart(context.Background()); err != nil {
+		t.Fatalf("OnStart: %v", err)
+	}
+	if err := hook.OnStop(context.Background()); err != nil {
+		t.Fatalf("OnStop: %v", err)
+	}
+	select {
+	case <-returned:
+	default:
+		t.Error("OnStop returned before the worker did")
+	}
+}
+
+func TestWorker_StopGivesUpAtDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	hook := Worker("stuck job", func(context.Context) { <-release })
+	if err := hook.OnStart(context.Background()); err != nil {
+		t.Fatalf("OnStart: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := hook.OnStop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("OnStop of a stuck worker = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDrain_OnlyStopsAfterEveryHookStarted(t *testing.T) {
+	tests := []struct {
+		name          string
+		failingServer bool
+		wantUnready   bool
+	}{
+		{name: "started", wantUnready: true},
+		{name: "failed start", failingServer: true, wantUnready: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			unready := false
+			m := New()
+			m.Append(r.hook("server", false, tt.failingServer, false))
+			m.Append(Drain(0, func() { unready = true }))
+
+			_ = m.Start(context.Background())
+			if err := m.Stop(context.Background()); err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+			if unready != tt.wantUnready {
+				t.Errorf("markUnready called = %v, want %v", unready, tt.wantUnready)
+			}
+		})
+	}
+}
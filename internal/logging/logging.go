@@ -0,0 +1,76 @@
+// Package logging builds the application's slog loggers and carries request-scoped loggers in contexts.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+// Options configures New
+type Options struct {
+	Level  slog.Leveler // minimum level; a *slog.LevelVar allows changing it at runtime
+	Format string       // json, text or pretty
+	// RedactKeys lists sensitive attribute keys. An attribute is redacted when its key contains one of them,
+	// ignoring case, so "token" also covers "access_token".
+	RedactKeys []string
+}
+
+// New creates a logger writing to w in the configured format
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{
+		Level:       opts.Level,
+		ReplaceAttr: redactor(opts.RedactKeys),
+	}
+	switch opts.Format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, handlerOpts))
+	case "pretty":
+		return slog.New(newPrettyHandler(w, handlerOpts))
+	}
+	return slog.New(slog.NewTextHandler(w, handlerOpts))
+}
+
+// redactor returns a ReplaceAttr function hiding the values of attributes with sensitive keys
+func redactor(keys []string) func(groups []string, a slog.Attr) slog.Attr {
+	lowered := make([]string, len(keys))
+	for i, key := range keys {
+		lowered[i] = strings.ToLower(key)
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if a.Value.Kind() == slog.KindGroup {
+			return a
+		}
+		key := strings.ToLower(a.Key)
+		for _, sensitive := range lowered {
+			if strings.Contains(key, sensitive) {
+				return slog.String(a.Key, Redacted)
+			}
+		}
+		return a
+	}
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the default logger for background work
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With returns a copy of ctx whose logger adds args to every record, e.g. the authenticated user
+func With(ctx context.Context, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(args...))
+}
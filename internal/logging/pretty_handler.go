@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ANSI escape sequences; NO_COLOR=1 turns them off, see https://no-color.org
+const (
+	ansiReset  = "\x1b[0m"
+	ansiFaint  = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+)
+
+// prettyHandler writes compact, colored lines for development, e.g.
+//
+//	10:04:05.123 INF request completed route=/api/v1/users/:id status=200 latency=1.2ms
+type prettyHandler struct {
+	opts   slog.HandlerOptions
+	color  bool
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  string   // preformatted attributes from WithAttrs
+	groups []string // open groups from WithGroup
+}
+
+func newPrettyHandler(w io.Writer, opts *slog.HandlerOptions) *prettyHandler {
+	return &prettyHandler{opts: *opts, color: os.Getenv("NO_COLOR") == "", mu: &sync.Mutex{}, w: w}
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *prettyHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	if !r.Time.IsZero() {
+		b.WriteString(h.paint(ansiFaint, r.Time.Format("15:04:05.000")))
+		b.WriteByte(' ')
+	}
+	b.WriteString(h.level(r.Level))
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		h.appendAttr(&b, h.groups, a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		h.appendAttr(&b, h.groups, a)
+	}
+	clone := *h
+	clone.attrs += b.String()
+	return &clone
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+// appendAttr writes " key=value", flattening groups into dotted keys
+func (h *prettyHandler) appendAttr(b *strings.Builder, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+		for _, member := range a.Value.Group() {
+			h.appendAttr(b, groups, member)
+		}
+		return
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	b.WriteByte(' ')
+	b.WriteString(h.paint(ansiFaint, key+"="))
+	b.WriteString(formatValue(a.Value))
+}
+
+func (h *prettyHandler) level(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return h.paint(ansiRed, "ERR")
+	case level >= slog.LevelWarn:
+		return h.paint(ansiYellow, "WRN")
+	case level >= slog.LevelInfo:
+		return h.paint(ansiGreen, "INF")
+	}
+	return h.paint(ansiBlue, "DBG")
+}
+
+func (h *prettyHandler) paint(color, s string) string {
+	if !h.color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// formatValue renders a value like the text handler does, quoting strings only when needed
+func formatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindString:
+		return quoteIfNeeded(v.String())
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return quoteIfNeeded(err.Error())
+		}
+		return quoteIfNeeded(fmt.Sprint(v.Any()))
+	}
+	return v.String()
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
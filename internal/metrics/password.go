@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// passwordHashDuration is global because hashing happens in util, far below the services that hold a Registry.
+// PBKDF2 with a million iterations takes hundreds of milliseconds, hence the coarse buckets.
+var passwordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: "password",
+	Name:      "hash_duration_seconds",
+	Help:      "Time spent hashing and verifying passwords.",
+	Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+}, []string{"operation"})
+
+// ObservePasswordHash records how long a password operation ("hash" or "verify") took since start
+func ObservePasswordHash(operation string, start time.Time) {
+	passwordHashDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,79 @@
+// Package metrics exposes the application's Prometheus metrics: HTTP traffic, connection pools,
+// password hashing, Go runtime and the domain metrics services register.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the names of the application's own metrics
+const Namespace = "app"
+
+// Registry holds the metrics served on /metrics. Services create their domain metrics through
+// it, so they share the namespace and are registered exactly once.
+type Registry struct {
+	reg *prometheus.Registry
+}
+
+// NewRegistry creates a registry with the Go runtime, process and password hashing metrics
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		passwordHashDuration,
+	)
+	return &Registry{reg: reg}
+}
+
+// MustRegister registers collectors such as the pool collectors; it panics on duplicate names.
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	r.reg.MustRegister(cs...)
+}
+
+// NewCounterVec creates and registers a counter named app_<subsystem>_<name>
+func (r *Registry) NewCounterVec(subsystem, name, help string, labels ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	r.reg.MustRegister(counter)
+	return counter
+}
+
+// NewHistogramVec creates and registers a histogram named app_<subsystem>_<name>; nil buckets
+// mean prometheus.DefBuckets.
+func (r *Registry) NewHistogramVec(subsystem, name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	r.reg.MustRegister(histogram)
+	return histogram
+}
+
+// NewGaugeVec creates and registers a gauge named app_<subsystem>_<name>
+func (r *Registry) NewGaugeVec(subsystem, name, help string, labels ...string) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+	r.reg.MustRegister(gauge)
+	return gauge
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{Registry: r.reg})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// RequireAdmin rejects requests whose authenticated user is not an admin.
+// It must run after RequireAuth. The flag is read from the database on every request,
+// so revoking admin rights takes effect immediately rather than when tokens expire.
+func RequireAdmin(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := UserID(c)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+		if err != nil && !errors.Is(err, apperror.NotFound) {
+			abortWithError(c, err)
+			return
+		}
+		if err != nil || !user.IsAdmin {
+			abortWithError(c, apperror.New(apperror.Forbidden, "admin privileges required"))
+			return
+		}
+
+		c.Next()
+	}
+}
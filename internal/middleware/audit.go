@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/audit"
+	"github.com/yourusername/yourprojectname/internal/requestid"
+)
+
+// AuditContext stores the request ID and client IP in the request context, so services can
+// attribute the changes they record. RequireAuth adds the authenticated user later in the chain.
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithMetadata(c.Request.Context(), audit.Metadata{
+			RequestID: requestid.FromContext(c.Request.Context()),
+			IP:        c.ClientIP(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSOptions configures a CORSPolicy
+type CORSOptions struct {
+	AllowedOrigins   []string // "*" allows any origin; empty disables CORS
+	AllowCredentials bool
+	MaxAge           time.Duration // how long browsers may cache a preflight response
+}
+
+// CORSPolicy answers cross-origin requests from the allowed origins.
+// Its options can be replaced at runtime with Update.
+type CORSPolicy struct {
+	options atomic.Pointer[CORSOptions]
+}
+
+// NewCORSPolicy creates a new instance of CORSPolicy
+func NewCORSPolicy(options CORSOptions) *CORSPolicy {
+	p := &CORSPolicy{}
+	p.Update(options)
+	return p
+}
+
+// Update replaces the options for all following requests
+func (p *CORSPolicy) Update(options CORSOptions) {
+	p.options.Store(&options)
+}
+
+// Handler adds the CORS headers for allowed origins and answers their preflight requests.
+// Requests from other origins pass through unchanged, so browsers block their responses.
+func (p *CORSPolicy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		options := p.options.Load()
+		origin := c.GetHeader("Origin")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin == "" || !slices.Contains(options.AllowedOrigins, origin) && !slices.Contains(options.AllowedOrigins, "*") {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		if options.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method != http.MethodOptions || c.GetHeader("Access-Control-Request-Method") == "" {
+			h.Set("Access-Control-Expose-Headers", "Content-Disposition, Content-Language, Retry-After, "+RequestIDHeader)
+			c.Next()
+			return
+		}
+		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+		if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		}
+		if options.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
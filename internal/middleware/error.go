@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/requestid"
+)
+
+// ProblemContentType is the media type of error responses (RFC 9457).
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the kind of a domain error to form the problem type URI
+const problemTypeBase = "urn:problem-type:"
+
+// Problem is an RFC 9457 problem details object.
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	RequestID string                `json:"request_id,omitempty"`
+	Errors    []apperror.FieldError `json:"errors,omitempty"`
+	// Extensions are written as additional top-level members
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON flattens Extensions into the problem object. Standard members win on collisions.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem // drops this method to avoid recursion
+	if len(p.Extensions) == 0 {
+		return json.Marshal(problem(p))
+	}
+	members := make(map[string]any, len(p.Extensions)+7)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	std, err := json.Marshal(problem(p))
+	if err != nil {
+		return nil, err
+	}
+	var stdMembers map[string]json.RawMessage
+	if err := json.Unmarshal(std, &stdMembers); err != nil {
+		return nil, err
+	}
+	for k, v := range stdMembers {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// problemKinds maps domain error kinds to their status code and title
+var problemKinds = map[apperror.Kind]struct {
+	status int
+	title  string
+}{
+	apperror.NotFound:     {http.StatusNotFound, "Resource not found"},
+	apperror.Conflict:     {http.StatusConflict, "Conflict with the current state"},
+	apperror.Validation:   {http.StatusBadRequest, "Invalid request"},
+	apperror.Unauthorized: {http.StatusUnauthorized, "Authentication required"},
+	apperror.Forbidden:    {http.StatusForbidden, "Access denied"},
+	apperror.TooLarge:     {http.StatusRequestEntityTooLarge, "Request too large"},
+	apperror.Unsupported:  {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	apperror.Unavailable:  {http.StatusServiceUnavailable, "Service unavailable"},
+	apperror.RateLimited:  {http.StatusTooManyRequests, "Too many requests"},
+	apperror.Internal:     {http.StatusInternalServerError, "Internal server error"},
+}
+
+// ErrorHandler writes the response for the last error a handler attached with c.Error, as
+// application/problem+json. Domain errors get the status and type of their kind and their message
+// as detail; context added by wrapping them is only logged. Anything else is logged and answered
+// with a generic 500, so driver and I/O details never reach clients. It must be registered before
+// the routes so it wraps every handler.
+//
+// Titles, details and field messages are translated into the stored locale of the authenticated
+// user, or else the best match for Accept-Language; userRepo is only consulted on errors.
+func ErrorHandler(bundle *i18n.Bundle, userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		locale := bundle.Match(storedLocale(c, userRepo), c.GetHeader("Accept-Language"))
+		problem := newProblem(err, bundle.Translator(locale))
+		problem.Instance = c.Request.URL.Path
+		problem.RequestID = requestid.FromContext(c.Request.Context())
+		ctx := c.Request.Context()
+		if problem.Status == http.StatusInternalServerError {
+			logging.FromContext(ctx).ErrorContext(ctx, "request failed", slog.Any("error", err))
+		} else if domainErr, ok := apperror.As(err); ok && err.Error() != domainErr.Message {
+			// The detail leaves out what wrapping added, so the full chain is kept for operators
+			logging.FromContext(ctx).InfoContext(ctx, "request rejected", slog.Any("error", err))
+		}
+		c.Abort()
+		c.Header("Content-Language", locale)
+		c.Render(problem.Status, problemRender{problem})
+	}
+}
+
+// storedLocale returns the locale the authenticated user chose, or "" if there is none
+func storedLocale(c *gin.Context, userRepo repository.UserRepository) string {
+	userID, err := UserID(c)
+	if err != nil {
+		return ""
+	}
+	user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		return ""
+	}
+	return user.Locale.String
+}
+
+// RouteNotFound answers requests that match no route with a problem instead of Gin's plain text.
+// Register it with router.NoRoute.
+func RouteNotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = c.Error(apperror.Newf(apperror.NotFound, "no route matches %s %s", c.Request.Method, c.Request.URL.Path))
+	}
+}
+
+// newProblem builds the problem for err in the language of translate, without the request-specific members.
+func newProblem(err error, translate func(string) string) Problem {
+	domainErr, ok := apperror.As(err)
+	if !ok {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			domainErr = apperror.Wrap(apperror.TooLarge, "request body exceeds the maximum upload size", err)
+			err = domainErr
+		} else {
+			return Problem{
+				Type:   "about:blank",
+				Title:  translate(problemKinds[apperror.Internal].title),
+				Status: http.StatusInternalServerError,
+			}
+		}
+	}
+
+	kind, ok := problemKinds[domainErr.Kind]
+	if !ok {
+		kind = problemKinds[apperror.Internal]
+	}
+	// Only the domain message is shown: context added by wrapping may hold driver or decoder
+	// details, so ErrorHandler logs it instead
+	detail := domainErr.Localize(translate)
+	var fields []apperror.FieldError
+	for _, f := range domainErr.Fields {
+		fields = append(fields, f.Localize(translate))
+	}
+	return Problem{
+		Type:       problemTypeBase + strings.ReplaceAll(string(domainErr.Kind), "_", "-"),
+		Title:      translate(kind.title),
+		Status:     kind.status,
+		Detail:     capitalize(detail),
+		Errors:     fields,
+		Extensions: domainErr.Extensions,
+	}
+}
+
+// problemRender writes a Problem with the problem+json content type
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ProblemContentType)
+}
+
+// capitalize matches the sentence case of the API's messages; domain messages follow Go's lower case
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// abortWithError stops the chain and leaves the response to ErrorHandler
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/i18n"
+)
+
+func TestNewProblem_Detail(t *testing.T) {
+	bundle, err := i18n.Load()
+	if err != nil {
+		t.Fatalf("i18n.Load: %v", err)
+	}
+	unsupported := apperror.New(apperror.Unsupported, "image must be a JPEG, PNG, GIF or WebP file")
+
+	tests := []struct {
+		name       string
+		err        error
+		locale     string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name:       "domain error",
+			err:        apperror.New(apperror.NotFound, "user not found"),
+			locale:     "en",
+			wantStatus: http.StatusNotFound,
+			wantDetail: "User not found",
+		},
+		{
+			name:       "translated domain error",
+			err:        apperror.New(apperror.NotFound, "user not found"),
+			locale:     "de",
+			wantStatus: http.StatusNotFound,
+			wantDetail: "Benutzer nicht gefunden",
+		},
+		{
+			name:       "formatted domain error",
+			err:        apperror.Newf(apperror.Validation, "invalid user: %s", "email is taken"),
+			locale:     "de",
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "Ungültiger Benutzer: email is taken",
+		},
+		{
+			name:       "context added by wrapping",
+			err:        fmt.Errorf("%w: %v", unsupported, errors.New("image: unknown format at offset 0x1f")),
+			locale:     "en",
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantDetail: "Image must be a JPEG, PNG, GIF or WebP file",
+		},
+		{
+			name:       "cause of a domain error",
+			err:        apperror.Wrap(apperror.Conflict, "user already exists", errors.New(`duplicate key value violates unique constraint "users_email_key"`)),
+			locale:     "en",
+			wantStatus: http.StatusConflict,
+			wantDetail: "User already exists",
+		},
+		{
+			name:       "other error",
+			err:        errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			locale:     "en",
+			wantStatus: http.StatusInternalServerError,
+			wantDetail: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := newProblem(tt.err, bundle.Translator(tt.locale))
+			if problem.Status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", problem.Status, tt.wantStatus)
+			}
+			if problem.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", problem.Detail, tt.wantDetail)
+			}
+			for _, leak := range []string{"offset", "duplicate key", "10.0.0.5"} {
+				if strings.Contains(problem.Detail, leak) {
+					t.Errorf("detail %q leaks %q", problem.Detail, leak)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+// ErrFeatureDisabled indicates that the requested route belongs to a switched off feature.
+var ErrFeatureDisabled = apperror.New(apperror.NotFound, "this feature is disabled")
+
+// FeatureToggle switches an optional feature on or off at runtime
+type FeatureToggle struct {
+	enabled atomic.Bool
+}
+
+// NewFeatureToggle creates a new instance of FeatureToggle
+func NewFeatureToggle(enabled bool) *FeatureToggle {
+	t := &FeatureToggle{}
+	t.enabled.Store(enabled)
+	return t
+}
+
+// Set switches the feature on or off for all following requests
+func (t *FeatureToggle) Set(enabled bool) {
+	t.enabled.Store(enabled)
+}
+
+// RequireFeature rejects requests while the feature is switched off, as if its routes did not exist.
+func RequireFeature(t *FeatureToggle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !t.enabled.Load() {
+			abortWithError(c, ErrFeatureDisabled)
+			return
+		}
+		c.Next()
+	}
+}
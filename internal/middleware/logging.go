@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestLogger puts a logger carrying the request ID, method, route and, for sampled traces, the trace
+// and span IDs into the request context and logs every request when it completes, with its status and
+// latency. It must be registered after RequestID; RequireAuth adds the user ID.
+// Only the path is logged, never the query string, which may hold tokens.
+func RequestLogger(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		attrs := []any{
+			slog.String("request_id", requestid.FromContext(c.Request.Context())),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsSampled() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+		}
+		logger := base.With(attrs...)
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+
+		c.Next()
+
+		ctx := c.Request.Context()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(ctx).LogAttrs(ctx, level, "request completed",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 problem response and logs it with its stack.
+// It must be registered after ErrorHandler, which renders the response.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered) // the client went away; net/http handles this quietly
+			}
+			ctx := c.Request.Context()
+			logging.FromContext(ctx).ErrorContext(ctx, "panic recovered", slog.Any("panic", recovered), slog.String("stack", string(debug.Stack())))
+			abortWithError(c, fmt.Errorf("panic: %v", recovered))
+		}()
+		c.Next()
+	}
+}
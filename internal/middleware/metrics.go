@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/metrics"
+)
+
+// unmatchedRoute labels requests that match no route, so scanners cannot create a series per path
+const unmatchedRoute = "unmatched"
+
+// RequestMetrics counts requests by method, route template and status and observes their latency.
+// Errors are the requests with a 5xx status. It must be registered before ErrorHandler, so the
+// status it records is the one ErrorHandler writes.
+func RequestMetrics(registry *metrics.Registry) gin.HandlerFunc {
+	requests := registry.NewCounterVec("http", "requests_total", "HTTP requests by method, route template and status.", "method", "route", "status")
+	duration := registry.NewHistogramVec("http", "request_duration_seconds", "HTTP request latency by method and route template.", nil, "method", "route")
+	inFlight := registry.NewGaugeVec("http", "requests_in_flight", "HTTP requests being served.").WithLabelValues()
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		duration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdle is how long a client's bucket is kept after its last request; a full bucket carries no state
+const rateLimitIdle = 5 * time.Minute
+
+// RateLimitOptions configures a RateLimiter
+type RateLimitOptions struct {
+	Enabled           bool
+	RequestsPerSecond int
+	Burst             int
+}
+
+// RateLimiter limits the requests of each client IP with a token bucket.
+// Buckets live in memory, so every instance enforces the limit separately.
+// Its options can be replaced at runtime with Update.
+type RateLimiter struct {
+	mu      sync.Mutex
+	options RateLimitOptions
+	clients map[string]*rateLimitClient
+}
+
+type rateLimitClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a new instance of RateLimiter
+func NewRateLimiter(options RateLimitOptions) *RateLimiter {
+	return &RateLimiter{options: options, clients: make(map[string]*rateLimitClient)}
+}
+
+// Update replaces the options. Existing buckets keep their tokens and adopt the new rate and burst.
+func (l *RateLimiter) Update(options RateLimitOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.options = options
+	for _, client := range l.clients {
+		client.limiter.SetLimit(rate.Limit(options.RequestsPerSecond))
+		client.limiter.SetBurst(options.Burst)
+	}
+}
+
+// Handler rejects requests exceeding the limit with 429 and a Retry-After header
+func (l *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := l.limiter(c.ClientIP())
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		reservation := limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			seconds := int(math.Ceil(delay.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			abortWithError(c, apperror.Newf(apperror.RateLimited, "rate limit exceeded, try again in %d seconds", seconds))
+			return
+		}
+		c.Next()
+	}
+}
+
+// limiter returns the bucket of a client, or nil while rate limiting is disabled
+func (l *RateLimiter) limiter(clientIP string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.options.Enabled {
+		return nil
+	}
+	client, ok := l.clients[clientIP]
+	if !ok {
+		client = &rateLimitClient{limiter: rate.NewLimiter(rate.Limit(l.options.RequestsPerSecond), l.options.Burst)}
+		l.clients[clientIP] = client
+	}
+	client.lastSeen = time.Now()
+	return client.limiter
+}
+
+// Run drops the buckets of idle clients until ctx is cancelled
+func (l *RateLimiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.mu.Lock()
+			for ip, client := range l.clients {
+				if now.Sub(client.lastSeen) > rateLimitIdle {
+					delete(l.clients, ip)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/requestid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// TraceparentHeader is the W3C Trace Context header; its trace ID is used when no request ID is sent.
+const TraceparentHeader = "traceparent"
+
+// RequestID stores the ID of the request in its context and echoes it in the X-Request-ID response
+// header. It takes a valid X-Request-ID from the caller, else the trace ID of the request's span or
+// traceparent header, else generates one. It must be registered right after Tracing, so every other
+// middleware sees the ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestid.Valid(id) {
+			id = traceID(c)
+		}
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// traceID returns the trace ID of the request, or a new ID if it is not part of a trace
+func traceID(c *gin.Context) string {
+	if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+		return spanContext.TraceID().String()
+	}
+	if id, ok := requestid.FromTraceparent(c.GetHeader(TraceparentHeader)); ok {
+		return id
+	}
+	return requestid.New()
+}
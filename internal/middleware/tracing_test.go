@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTracedRouter returns a router with the Tracing middleware recording every span into the returned recorder
+func newTracedRouter(t *testing.T) (*gin.Engine, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(tracing.NewTracerProvider(recorder, tracing.Options{ServiceName: "test", SampleRatio: 1}))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Tracing())
+	router.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(errors.New("database unreachable"))
+		c.Status(http.StatusInternalServerError)
+	})
+	return router, recorder
+}
+
+func serveTraced(t *testing.T, router *gin.Engine, recorder *tracetest.SpanRecorder, req *http.Request) sdktrace.ReadOnlySpan {
+	t.Helper()
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d ended spans, want 1", len(spans))
+	}
+	return spans[0]
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing_NamesSpanAfterRouteTemplate(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	span := serveTraced(t, router, recorder, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if span.Name() != "GET /users/:id" {
+		t.Errorf("span name = %q, want %q", span.Name(), "GET /users/:id")
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind())
+	}
+	if got := spanAttribute(span, semconv.HTTPRouteKey).AsString(); got != "/users/:id" {
+		t.Errorf("http.route = %q, want %q", got, "/users/:id")
+	}
+	if got := spanAttribute(span, semconv.URLPathKey).AsString(); got != "/users/42" {
+		t.Errorf("url.path = %q, want %q", got, "/users/42")
+	}
+	if got := spanAttribute(span, semconv.HTTPResponseStatusCodeKey).AsInt64(); got != http.StatusNoContent {
+		t.Errorf("http.response.status_code = %d, want %d", got, http.StatusNoContent)
+	}
+	if span.Status().Code != codes.Unset {
+		t.Errorf("span status = %v, want unset", span.Status().Code)
+	}
+}
+
+func TestTracing_ServerErrorFailsSpan(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	span := serveTraced(t, router, recorder, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if got := spanAttribute(span, semconv.HTTPResponseStatusCodeKey).AsInt64(); got != http.StatusInternalServerError {
+		t.Errorf("http.response.status_code = %d, want %d", got, http.StatusInternalServerError)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("span status = %v, want error", span.Status().Code)
+	}
+	if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+		t.Errorf("span events = %v, want the recorded handler error", span.Events())
+	}
+}
+
+func TestTracing_UnmatchedRoute(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+
+	span := serveTraced(t, router, recorder, httptest.NewRequest(http.MethodGet, "/no/such/path", nil))
+
+	// The path is unbounded, so it stays out of the name
+	if span.Name() != "GET" {
+		t.Errorf("span name = %q, want GET", span.Name())
+	}
+	if got := spanAttribute(span, semconv.HTTPResponseStatusCodeKey).AsInt64(); got != http.StatusNotFound {
+		t.Errorf("http.response.status_code = %d, want %d", got, http.StatusNotFound)
+	}
+	// Client errors do not fail a server span
+	if span.Status().Code != codes.Unset {
+		t.Errorf("span status = %v, want unset", span.Status().Code)
+	}
+}
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	router, recorder := newTracedRouter(t)
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	span := serveTraced(t, router, recorder, req)
+
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the one of traceparent", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span ID = %s, want the one of traceparent", got)
+	}
+	if !span.Parent().IsRemote() {
+		t.Error("parent span is not remote")
+	}
+}
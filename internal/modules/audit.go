@@ -0,0 +1,50 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// AuditModule records changes in the partitioned audit_events table and lets admins query them.
+// It provides service.AuditLogger and service.AuditService, so it must be registered before the modules recording events.
+type AuditModule struct{}
+
+// NewAuditModule creates a new instance of AuditModule.
+func NewAuditModule() *AuditModule {
+	return &AuditModule{}
+}
+
+// Name implements app.Module
+func (m *AuditModule) Name() string {
+	return "audit"
+}
+
+// Register implements app.Module
+func (m *AuditModule) Register(a *app.App) error {
+	auditEventRepo := repository.NewDBAuditEventRepository(a.Queries, a.DB)
+	auditService := service.NewAuditService(auditEventRepo, a.Config.Audit.Retention)
+	// The first maintenance runs once the lifecycle starts, not here, so registering touches no database
+	a.AddJob("audit partition maintenance", auditService.Run)
+	app.Provide[service.AuditService](a, auditService)
+	app.Provide[service.AuditLogger](a, auditService)
+
+	// Registered after the tables of the other modules, so the events about a user are redacted first on erasure
+	a.AfterRegister(func() error {
+		a.Privacy.Register(auditEventRepo)
+		return nil
+	})
+
+	auditHandler := handler.NewAuditHandler(auditService)
+	a.AddRoutes(func(r app.Routes) error {
+		requireAdmin, err := requireAdmin(a)
+		if err != nil {
+			return err
+		}
+		app_router.SetupAuditRoutes(r.API, auditHandler, requireAuth(a), requireAdmin)
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,35 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/storage"
+)
+
+// BlobsModule serves the signed download URLs of the local blob store; S3 serves its own.
+type BlobsModule struct{}
+
+// NewBlobsModule creates a new instance of BlobsModule.
+func NewBlobsModule() *BlobsModule {
+	return &BlobsModule{}
+}
+
+// Name implements app.Module
+func (m *BlobsModule) Name() string {
+	return "blobs"
+}
+
+// Register implements app.Module
+func (m *BlobsModule) Register(a *app.App) error {
+	localStore, ok := a.BlobStore.(*storage.LocalBlobStore)
+	if !ok {
+		return nil
+	}
+	blobHandler := handler.NewBlobHandler(localStore)
+	a.AddRoutes(func(r app.Routes) error {
+		app_router.SetupBlobRoutes(r.API, blobHandler)
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,52 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// EmailChangeModule changes email addresses after confirmation by the new address, revertible from the old one.
+// It needs the audit and users modules.
+type EmailChangeModule struct{}
+
+// NewEmailChangeModule creates a new instance of EmailChangeModule.
+func NewEmailChangeModule() *EmailChangeModule {
+	return &EmailChangeModule{}
+}
+
+// Name implements app.Module
+func (m *EmailChangeModule) Name() string {
+	return "email change"
+}
+
+// Register implements app.Module
+func (m *EmailChangeModule) Register(a *app.App) error {
+	auditLogger, err := app.Get[service.AuditLogger](a)
+	if err != nil {
+		return err
+	}
+	userRepo, err := app.Get[repository.UserRepository](a)
+	if err != nil {
+		return err
+	}
+	cfg := a.Config
+
+	emailChangeRepo := repository.NewDBEmailChangeRepository(a.Queries)
+	emailChangeService := service.NewEmailChangeService(userRepo, emailChangeRepo, a.Mailer, auditLogger, service.EmailChangeConfig{
+		ConfirmURL: cfg.FrontendURL + "/email-change/confirm",
+		RevertURL:  cfg.FrontendURL + "/email-change/revert",
+		ConfirmTTL: cfg.EmailChange.ConfirmTTL,
+		RevertTTL:  cfg.EmailChange.RevertTTL,
+	})
+	a.Privacy.Register(emailChangeRepo)
+
+	emailChangeHandler := handler.NewEmailChangeHandler(emailChangeService)
+	a.AddRoutes(func(r app.Routes) error {
+		app_router.SetupEmailChangeRoutes(r.API, emailChangeHandler)
+		return nil
+	})
+	return nil
+}
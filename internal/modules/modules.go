@@ -0,0 +1,32 @@
+// Package modules holds the built-in features of the application as app.Module implementations.
+// Each module wires its repository, service, handler and routes; cmd/server composes them.
+package modules
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/middleware"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// requireAuth authenticates requests with the current secret key
+func requireAuth(a *app.App) gin.HandlerFunc {
+	return middleware.RequireAuth(a.Config.Secret("auth.secret_key"))
+}
+
+// requireAdmin must run after requireAuth; it needs the users module to be registered
+func requireAdmin(a *app.App) (gin.HandlerFunc, error) {
+	userRepo, err := app.Get[repository.UserRepository](a)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.RequireAdmin(userRepo), nil
+}
+
+// featureToggle follows a reloadable feature flag of the configuration
+func featureToggle(a *app.App, flag func(c *config.Config) bool) gin.HandlerFunc {
+	toggle := middleware.NewFeatureToggle(flag(a.Config))
+	config.Subscribe(a.Watcher, flag, toggle.Set)
+	return middleware.RequireFeature(toggle)
+}
@@ -0,0 +1,54 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// PrivacyModule exports and erases everything stored about the current user, across the tables
+// registered with app.App.Privacy. It needs the audit and users modules.
+type PrivacyModule struct{}
+
+// NewPrivacyModule creates a new instance of PrivacyModule.
+func NewPrivacyModule() *PrivacyModule {
+	return &PrivacyModule{}
+}
+
+// Name implements app.Module
+func (m *PrivacyModule) Name() string {
+	return "privacy"
+}
+
+// Register implements app.Module
+func (m *PrivacyModule) Register(a *app.App) error {
+	auditLogger, err := app.Get[service.AuditLogger](a)
+	if err != nil {
+		return err
+	}
+	userRepo, err := app.Get[repository.UserRepository](a)
+	if err != nil {
+		return err
+	}
+	cfg := a.Config
+
+	dataExportRepo := repository.NewDBDataExportRepository(a.Queries)
+	privacyService := service.NewPrivacyService(userRepo, dataExportRepo, a.Privacy, a.BlobStore, auditLogger, service.PrivacyConfig{
+		Workers:   cfg.Export.Workers,
+		ExportTTL: cfg.Export.TTL,
+		URLTTL:    cfg.Blob.URLTTL,
+	})
+	a.Privacy.Register(dataExportRepo)
+	a.AddJob("data export workers", privacyService.Run)
+
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	requireExport := featureToggle(a, func(c *config.Config) bool { return c.Features.DataExport })
+	a.AddRoutes(func(r app.Routes) error {
+		app_router.SetupPrivacyRoutes(r.API, privacyHandler, requireAuth(a), requireExport)
+		return nil
+	})
+	return nil
+}
@@ -0,0 +1,46 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/middleware"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// UserImportModule lets admins bulk-import users from CSV or NDJSON. It needs the audit and users modules.
+type UserImportModule struct{}
+
+// NewUserImportModule creates a new instance of UserImportModule.
+func NewUserImportModule() *UserImportModule {
+	return &UserImportModule{}
+}
+
+// Name implements app.Module
+func (m *UserImportModule) Name() string {
+	return "user import"
+}
+
+// Register implements app.Module
+func (m *UserImportModule) Register(a *app.App) error {
+	auditLogger, err := app.Get[service.AuditLogger](a)
+	if err != nil {
+		return err
+	}
+	userRepo, err := app.Get[repository.UserRepository](a)
+	if err != nil {
+		return err
+	}
+	cfg := a.Config
+
+	userImportService := service.NewUserImportService(userRepo, auditLogger, cfg.Import.HashWorkers)
+	userImportHandler := handler.NewUserImportHandler(userImportService, cfg.Import.MaxBytes, cfg.Import.BatchSize)
+	requireImport := featureToggle(a, func(c *config.Config) bool { return c.Features.UserImport })
+	a.AddRoutes(func(r app.Routes) error {
+		app_router.SetupUserImportRoutes(r.API, userImportHandler, requireImport, requireAuth(a), middleware.RequireAdmin(userRepo))
+		return nil
+	})
+	return nil
+}
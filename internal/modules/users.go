@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"github.com/yourusername/yourprojectname/config"
+	"github.com/yourusername/yourprojectname/internal/app"
+	"github.com/yourusername/yourprojectname/internal/handler"
+	"github.com/yourusername/yourprojectname/internal/middleware"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	app_router "github.com/yourusername/yourprojectname/internal/router"
+	"github.com/yourusername/yourprojectname/internal/service"
+)
+
+// UsersModule serves the user resource with its avatars and the admin export.
+// It needs the audit module and provides repository.UserRepository and service.UserService.
+type UsersModule struct{}
+
+// NewUsersModule creates a new instance of UsersModule.
+func NewUsersModule() *UsersModule {
+	return &UsersModule{}
+}
+
+// Name implements app.Module
+func (m *UsersModule) Name() string {
+	return "users"
+}
+
+// Register implements app.Module
+func (m *UsersModule) Register(a *app.App) error {
+	auditLogger, err := app.Get[service.AuditLogger](a)
+	if err != nil {
+		return err
+	}
+	cfg := a.Config
+
+	userRepo := repository.NewDBUserRepository(a.Queries, a.DB)
+	userService := service.NewUserService(userRepo, auditLogger, a.Metrics, service.UserServiceConfig{
+		BatchWindow: cfg.Users.BatchWindow,
+		MaxBatch:    cfg.Users.BatchMaxSize,
+		Timeout:     cfg.Users.BatchTimeout,
+	})
+	avatarService := service.NewAvatarService(userRepo, a.BlobStore, auditLogger, cfg.Users.AvatarMaxBytes, cfg.Blob.URLTTL)
+	userExportService := service.NewUserExportService(userRepo)
+	app.Provide[repository.UserRepository](a, userRepo)
+	app.Provide[service.UserService](a, userService)
+	// The users table is the parent of every other table holding personal data
+	a.Privacy.Register(userRepo, avatarService)
+
+	userHandler := handler.NewUserHandler(userService, avatarService, a.I18n, cfg.Users.BatchGetMaxKeys)
+	userExportHandler := handler.NewUserExportHandler(userExportService)
+	requireAvatars := featureToggle(a, func(c *config.Config) bool { return c.Features.Avatars })
+	a.AddRoutes(func(r app.Routes) error {
+		optionalAuth := middleware.OptionalAuth(cfg.Secret("auth.secret_key"))
+		requireAdmin := middleware.RequireAdmin(userRepo)
+		app_router.SetupUserRoutes(r.API, userHandler, userExportHandler, optionalAuth, requireAuth(a), requireAdmin, requireAvatars)
+		return nil
+	})
+	return nil
+}
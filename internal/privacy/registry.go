@@ -0,0 +1,90 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ExportWriter receives the files a Hook contributes to a user's data export
+type ExportWriter interface {
+	// WriteJSON adds name to the archive containing v encoded as indented JSON
+	WriteJSON(name string, v any) error
+	// WriteFile adds name to the archive containing the content of r
+	WriteFile(name string, r io.Reader) error
+}
+
+// Hook is implemented by every component that stores personal data.
+// Repositories register themselves with a Registry so exports and erasures cover all tables.
+type Hook interface {
+	// PrivacyHookName identifies the hook; exported files are placed in a directory of this name
+	PrivacyHookName() string
+	// ExportUserData writes everything the component stores about userID
+	ExportUserData(ctx context.Context, userID int64, w ExportWriter) error
+	// EraseUserData deletes or anonymizes everything the component stores about userID.
+	// It must be idempotent, as a failed erasure is retried from the start.
+	EraseUserData(ctx context.Context, userID int64) error
+}
+
+// Registry holds the privacy hooks of all components
+type Registry struct {
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+// NewRegistry creates a new, empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds hooks to the registry.
+// Hooks are exported in registration order and erased in reverse order, so the owner of a
+// parent row (e.g. users) should be registered before components that reference it.
+func (r *Registry) Register(hooks ...Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hooks...)
+}
+
+// Hooks returns the registered hooks in registration order
+func (r *Registry) Hooks() []Hook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Hook(nil), r.hooks...)
+}
+
+// Export runs every hook's export against w
+func (r *Registry) Export(ctx context.Context, userID int64, w ExportWriter) error {
+	for _, h := range r.Hooks() {
+		if err := h.ExportUserData(ctx, userID, &prefixedWriter{prefix: h.PrivacyHookName() + "/", w: w}); err != nil {
+			return fmt.Errorf("privacy export %s: %w", h.PrivacyHookName(), err)
+		}
+	}
+	return nil
+}
+
+// Erase runs every hook's erasure in reverse registration order and stops at the first failure
+func (r *Registry) Erase(ctx context.Context, userID int64) error {
+	hooks := r.Hooks()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].EraseUserData(ctx, userID); err != nil {
+			return fmt.Errorf("privacy erase %s: %w", hooks[i].PrivacyHookName(), err)
+		}
+	}
+	return nil
+}
+
+// prefixedWriter places a hook's files in its own directory
+type prefixedWriter struct {
+	prefix string
+	w      ExportWriter
+}
+
+func (p *prefixedWriter) WriteJSON(name string, v any) error {
+	return p.w.WriteJSON(p.prefix+name, v)
+}
+
+func (p *prefixedWriter) WriteFile(name string, r io.Reader) error {
+	return p.w.WriteFile(p.prefix+name, r)
+}
@@ -0,0 +1,52 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ZipExportWriter writes an export as a ZIP archive
+type ZipExportWriter struct {
+	zw *zip.Writer
+}
+
+// NewZipExportWriter creates a new instance of ZipExportWriter writing to w
+func NewZipExportWriter(w io.Writer) *ZipExportWriter {
+	return &ZipExportWriter{zw: zip.NewWriter(w)}
+}
+
+// WriteJSON adds a JSON file to the archive
+func (z *ZipExportWriter) WriteJSON(name string, v any) error {
+	f, err := z.create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteFile adds a file to the archive
+func (z *ZipExportWriter) WriteFile(name string, r io.Reader) error {
+	f, err := z.create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Close writes the archive directory. It does not close the underlying writer.
+func (z *ZipExportWriter) Close() error {
+	return z.zw.Close()
+}
+
+func (z *ZipExportWriter) create(name string) (io.Writer, error) {
+	return z.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+}
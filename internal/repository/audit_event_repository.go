@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+)
+
+// auditPartitionPrefix names monthly partitions, e.g. audit_events_p202610
+const auditPartitionPrefix = "audit_events_p"
+
+// AuditEventRepository defines methods for audit_events table
+type AuditEventRepository interface {
+	CreateAuditEvent(ctx context.Context, arg sqlc.CreateAuditEventParams) (sqlc.AuditEvent, error)
+	ListAuditEvents(ctx context.Context, arg sqlc.ListAuditEventsParams) ([]sqlc.AuditEvent, error)
+	EnsureAuditPartitions(ctx context.Context, from time.Time, months int) error
+	DropAuditPartitionsBefore(ctx context.Context, cutoff time.Time) ([]string, error)
+	privacy.Hook
+}
+
+// DBAuditEventRepository takes sqlc.Querier to create an instance
+// db runs the partition DDL, which sqlc cannot express
+type DBAuditEventRepository struct {
+	q  sqlc.Querier
+	db TxDB
+}
+
+// NewDBAuditEventRepository creates a new instance of DBAuditEventRepository
+func NewDBAuditEventRepository(querier sqlc.Querier, db TxDB) AuditEventRepository {
+	return &DBAuditEventRepository{q: querier, db: db}
+}
+
+// CreateAuditEvent appends an event to the audit log
+func (r *DBAuditEventRepository) CreateAuditEvent(ctx context.Context, arg sqlc.CreateAuditEventParams) (sqlc.AuditEvent, error) {
+	event, err := r.q.CreateAuditEvent(ctx, arg)
+	return event, translateError(err, "audit event")
+}
+
+// ListAuditEvents retrieves a page of events, newest first
+func (r *DBAuditEventRepository) ListAuditEvents(ctx context.Context, arg sqlc.ListAuditEventsParams) ([]sqlc.AuditEvent, error) {
+	events, err := r.q.ListAuditEvents(ctx, arg)
+	return events, translateError(err, "audit event")
+}
+
+// EnsureAuditPartitions creates the monthly partitions for the month of from and the following months
+func (r *DBAuditEventRepository) EnsureAuditPartitions(ctx context.Context, from time.Time, months int) error {
+	start := monthStart(from)
+	for i := 0; i <= months; i++ {
+		lower := start.AddDate(0, i, 0)
+		if err := r.createAuditPartition(ctx, lower, lower.AddDate(0, 1, 0)); err != nil {
+			return fmt.Errorf("failed to create audit partition for %s: %w", lower.Format("2006-01"), err)
+		}
+	}
+	return nil
+}
+
+// createAuditPartition creates the partition for [lower, upper) unless it exists.
+// Postgres refuses to add a partition while the default partition holds rows of its range,
+// e.g. events written before the partitions were prepared, so those rows are moved into it first.
+func (r *DBAuditEventRepository) createAuditPartition(ctx context.Context, lower, upper time.Time) error {
+	name := auditPartitionPrefix + lower.Format("200601")
+	table := pgx.Identifier{name}.Sanitize()
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Attaching locks the default partition anyway; taking the lock first keeps new rows of the range
+	// out of it until the partition is attached, and serializes replicas creating the same partition
+	if _, err := tx.Exec(ctx, "LOCK TABLE audit_events_default IN ACCESS EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", name).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TABLE %s (LIKE audit_events INCLUDING DEFAULTS)", table)); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, fmt.Sprintf(`
+		WITH moved AS (
+			DELETE FROM audit_events_default WHERE occurred_at >= $1 AND occurred_at < $2 RETURNING *
+		)
+		INSERT INTO %s SELECT * FROM moved`, table), lower, upper)
+	if err != nil {
+		return err
+	}
+	// Partition bounds cannot be bind parameters; both values are formatted here, never user input
+	attach := fmt.Sprintf(
+		"ALTER TABLE audit_events ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')",
+		table, lower.Format(time.RFC3339), upper.Format(time.RFC3339),
+	)
+	if _, err := tx.Exec(ctx, attach); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DropAuditPartitionsBefore drops monthly partitions that end before cutoff and deletes
+// older rows from the default partition. It returns the names of the dropped partitions.
+func (r *DBAuditEventRepository) DropAuditPartitionsBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'audit_events'`)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, auditPartitionPrefix)
+		if !ok {
+			continue
+		}
+		month, err := time.Parse("200601", suffix)
+		if err != nil || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if _, err := r.db.Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return dropped, fmt.Errorf("failed to drop audit partition %s: %w", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+
+	if _, err := r.db.Exec(ctx, "DELETE FROM audit_events_default WHERE occurred_at < $1", cutoff); err != nil {
+		return dropped, err
+	}
+	return dropped, nil
+}
+
+// PrivacyHookName identifies the audit_events table in data exports
+func (r *DBAuditEventRepository) PrivacyHookName() string {
+	return "audit_events"
+}
+
+// ExportUserData exports the events about the user and those performed by the user
+func (r *DBAuditEventRepository) ExportUserData(ctx context.Context, userID int64, w privacy.ExportWriter) error {
+	events, err := r.q.ListAuditEventsForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	history := make([]map[string]any, 0, len(events))
+	for _, e := range events {
+		history = append(history, map[string]any{
+			"occurred_at": e.OccurredAt,
+			"actor_id":    e.ActorID,
+			"action":      e.Action,
+			"target_type": e.TargetType,
+			"target_id":   e.TargetID,
+			"changes":     json.RawMessage(e.Changes),
+			"ip":          e.Ip,
+		})
+	}
+	return w.WriteJSON("events.json", history)
+}
+
+// EraseUserData redacts diffs about the user and the user's IP addresses; the events themselves are kept
+func (r *DBAuditEventRepository) EraseUserData(ctx context.Context, userID int64) error {
+	return translateError(r.q.RedactAuditEventsForUser(ctx, userID), "audit event")
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
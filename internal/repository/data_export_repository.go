@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+)
+
+// DataExportRepository defines methods for data_exports table
+type DataExportRepository interface {
+	CreateDataExport(ctx context.Context, userID int64) (sqlc.DataExport, error)
+	GetDataExport(ctx context.Context, arg sqlc.GetDataExportParams) (sqlc.DataExport, error)
+	ListDataExportsByUser(ctx context.Context, userID int64) ([]sqlc.DataExport, error)
+	StartDataExport(ctx context.Context, id int64) (sqlc.DataExport, error)
+	CompleteDataExport(ctx context.Context, arg sqlc.CompleteDataExportParams) error
+	FailDataExport(ctx context.Context, arg sqlc.FailDataExportParams) error
+	FailStaleDataExports(ctx context.Context, createdBefore time.Time) error
+	ListExpiredDataExports(ctx context.Context) ([]sqlc.DataExport, error)
+	DeleteDataExport(ctx context.Context, id int64) error
+	privacy.Hook
+}
+
+// DBDataExportRepository takes sqlc.Querier to create an instance
+type DBDataExportRepository struct {
+	q sqlc.Querier
+}
+
+// NewDBDataExportRepository creates a new instance of DBDataExportRepository
+func NewDBDataExportRepository(querier sqlc.Querier) DataExportRepository {
+	return &DBDataExportRepository{q: querier}
+}
+
+// CreateDataExport queues a new export for a user
+func (r *DBDataExportRepository) CreateDataExport(ctx context.Context, userID int64) (sqlc.DataExport, error) {
+	export, err := r.q.CreateDataExport(ctx, userID)
+	return export, translateError(err, "data export")
+}
+
+// GetDataExport retrieves an export owned by a user
+func (r *DBDataExportRepository) GetDataExport(ctx context.Context, arg sqlc.GetDataExportParams) (sqlc.DataExport, error) {
+	export, err := r.q.GetDataExport(ctx, arg)
+	return export, translateError(err, "data export")
+}
+
+// ListDataExportsByUser retrieves all exports of a user, newest first
+func (r *DBDataExportRepository) ListDataExportsByUser(ctx context.Context, userID int64) ([]sqlc.DataExport, error) {
+	exports, err := r.q.ListDataExportsByUser(ctx, userID)
+	return exports, translateError(err, "data export")
+}
+
+// StartDataExport moves a pending export to running; it returns no rows if another worker claimed it
+func (r *DBDataExportRepository) StartDataExport(ctx context.Context, id int64) (sqlc.DataExport, error) {
+	export, err := r.q.StartDataExport(ctx, id)
+	return export, translateError(err, "data export")
+}
+
+// CompleteDataExport records the archive of a finished export
+func (r *DBDataExportRepository) CompleteDataExport(ctx context.Context, arg sqlc.CompleteDataExportParams) error {
+	return translateError(r.q.CompleteDataExport(ctx, arg), "data export")
+}
+
+// FailDataExport records the error of a failed export
+func (r *DBDataExportRepository) FailDataExport(ctx context.Context, arg sqlc.FailDataExportParams) error {
+	return translateError(r.q.FailDataExport(ctx, arg), "data export")
+}
+
+// FailStaleDataExports fails unfinished exports created before the current process started
+func (r *DBDataExportRepository) FailStaleDataExports(ctx context.Context, createdBefore time.Time) error {
+	return translateError(r.q.FailStaleDataExports(ctx, createdBefore), "data export")
+}
+
+// ListExpiredDataExports retrieves completed exports past their expiry
+func (r *DBDataExportRepository) ListExpiredDataExports(ctx context.Context) ([]sqlc.DataExport, error) {
+	exports, err := r.q.ListExpiredDataExports(ctx)
+	return exports, translateError(err, "data export")
+}
+
+// DeleteDataExport deletes an export record
+func (r *DBDataExportRepository) DeleteDataExport(ctx context.Context, id int64) error {
+	return translateError(r.q.DeleteDataExport(ctx, id), "data export")
+}
+
+// PrivacyHookName identifies the data_exports table in data exports
+func (r *DBDataExportRepository) PrivacyHookName() string {
+	return "data_exports"
+}
+
+// ExportUserData exports the history of previous exports
+func (r *DBDataExportRepository) ExportUserData(ctx context.Context, userID int64, w privacy.ExportWriter) error {
+	exports, err := r.q.ListDataExportsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	history := make([]map[string]any, 0, len(exports))
+	for _, e := range exports {
+		history = append(history, map[string]any{
+			"id":           e.ID,
+			"status":       e.Status,
+			"created_at":   e.CreatedAt,
+			"completed_at": e.CompletedAt,
+		})
+	}
+	return w.WriteJSON("exports.json", history)
+}
+
+// EraseUserData deletes all export records of a user. Archives are removed by the privacy service.
+func (r *DBDataExportRepository) EraseUserData(ctx context.Context, userID int64) error {
+	return translateError(r.q.DeleteDataExportsByUser(ctx, userID), "data export")
+}
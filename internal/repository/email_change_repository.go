@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+)
+
+// EmailChangeRepository defines methods for email_change_requests table
+type EmailChangeRepository interface {
+	CancelPendingEmailChanges(ctx context.Context, userID int64) error
+	CreateEmailChangeRequest(ctx context.Context, arg sqlc.CreateEmailChangeRequestParams) (sqlc.EmailChangeRequest, error)
+	ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (sqlc.User, error)
+	RevertEmailChange(ctx context.Context, revertTokenHash string) (sqlc.RevertEmailChangeRow, error)
+	privacy.Hook
+}
+
+// DBEmailChangeRepository takes sqlc.Querier to create an instance
+type DBEmailChangeRepository struct {
+	q sqlc.Querier
+}
+
+// NewDBEmailChangeRepository creates a new instance of DBEmailChangeRepository
+func NewDBEmailChangeRepository(querier sqlc.Querier) EmailChangeRepository {
+	return &DBEmailChangeRepository{q: querier}
+}
+
+// CancelPendingEmailChanges cancels the open request of a user, if any
+func (r *DBEmailChangeRepository) CancelPendingEmailChanges(ctx context.Context, userID int64) error {
+	return translateError(r.q.CancelPendingEmailChanges(ctx, userID), "email change request")
+}
+
+// CreateEmailChangeRequest stores a new pending request
+func (r *DBEmailChangeRepository) CreateEmailChangeRequest(ctx context.Context, arg sqlc.CreateEmailChangeRequestParams) (sqlc.EmailChangeRequest, error) {
+	req, err := r.q.CreateEmailChangeRequest(ctx, arg)
+	return req, translateError(err, "email change request")
+}
+
+// ConfirmEmailChange atomically confirms a request and swaps the user's email
+func (r *DBEmailChangeRepository) ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (sqlc.User, error) {
+	user, err := r.q.ConfirmEmailChange(ctx, confirmTokenHash)
+	return user, translateError(err, "email change request")
+}
+
+// RevertEmailChange cancels a pending request or restores the previous email of a confirmed one
+func (r *DBEmailChangeRepository) RevertEmailChange(ctx context.Context, revertTokenHash string) (sqlc.RevertEmailChangeRow, error) {
+	row, err := r.q.RevertEmailChange(ctx, revertTokenHash)
+	return row, translateError(err, "email change request")
+}
+
+// PrivacyHookName identifies the email_change_requests table in data exports
+func (r *DBEmailChangeRepository) PrivacyHookName() string {
+	return "email_changes"
+}
+
+// ExportUserData exports the email change history of a user, without token hashes
+func (r *DBEmailChangeRepository) ExportUserData(ctx context.Context, userID int64, w privacy.ExportWriter) error {
+	requests, err := r.q.ListEmailChangeRequestsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	history := make([]map[string]any, 0, len(requests))
+	for _, req := range requests {
+		history = append(history, map[string]any{
+			"old_email":    req.OldEmail,
+			"new_email":    req.NewEmail,
+			"created_at":   req.CreatedAt,
+			"confirmed_at": req.ConfirmedAt,
+			"reverted_at":  req.RevertedAt,
+		})
+	}
+	return w.WriteJSON("requests.json", history)
+}
+
+// EraseUserData deletes all email change requests of a user
+func (r *DBEmailChangeRepository) EraseUserData(ctx context.Context, userID int64) error {
+	return translateError(r.q.DeleteEmailChangeRequestsByUser(ctx, userID), "email change request")
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+// uniqueViolation is the PostgreSQL SQLSTATE for unique_violation
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err was caused by a unique constraint.
+// If constraint is not empty, the violated constraint must match it as well.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return false
+	}
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
+
+// PostgreSQL SQLSTATE codes translated into domain errors
+const (
+	foreignKeyViolation = "23503"
+	notNullViolation    = "23502"
+	checkViolation      = "23514"
+	stringTooLong       = "22001"
+	invalidTextFormat   = "22P02"
+)
+
+// constraintMessages gives client-safe messages for unique constraints; others fall back to a generic one
+var constraintMessages = map[string]string{
+	"users_email_key": "email already in use",
+	"email_change_requests_one_pending_per_user": "another email change is in progress",
+}
+
+// translateError turns pgx errors into domain errors; entity names the record in messages.
+// The original error stays in the chain, so pgx.ErrNoRows and IsUniqueViolation keep working.
+func translateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.Wrap(apperror.NotFound, entity+" not found", err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		if msg, ok := constraintMessages[pgErr.ConstraintName]; ok {
+			return apperror.Wrap(apperror.Conflict, msg, err)
+		}
+		return apperror.Wrap(apperror.Conflict, entity+" already exists", err)
+	case foreignKeyViolation:
+		return apperror.Wrap(apperror.Conflict, entity+" references a record that does not exist or is still referenced", err)
+	case notNullViolation, checkViolation, stringTooLong, invalidTextFormat:
+		return apperror.Wrapf(apperror.Validation, err, "invalid "+entity+": %s", pgErr.Message)
+	}
+	return err
+}
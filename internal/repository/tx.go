@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+)
+
+// TxBeginner starts transactions; *pgxpool.Pool satisfies it
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxDB runs statements and starts transactions; *pgxpool.Pool satisfies it
+type TxDB interface {
+	sqlc.DBTX
+	TxBeginner
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+)
+
+// UserExportRow is the exported projection of a User.
+// It deliberately has no password field, so hashes cannot leak into dumps.
+type UserExportRow struct {
+	ID        int64     `json:"id" parquet:"id"`
+	FirstName string    `json:"first_name" parquet:"first_name"`
+	LastName  string    `json:"last_name" parquet:"last_name"`
+	Email     string    `json:"email" parquet:"email"`
+	IsAdmin   bool      `json:"is_admin" parquet:"is_admin"`
+	CreatedAt time.Time `json:"created_at" parquet:"created_at,timestamp(microsecond)"`
+	UpdatedAt time.Time `json:"updated_at" parquet:"updated_at,timestamp(microsecond)"`
+}
+
+// userExportCursor is the cursor name used by StreamUsers; it only lives inside its transaction
+const userExportCursor = "user_export_cursor"
+
+// userFilterSQL selects the exported columns with the same optional filters as the ListUsers query
+const userFilterSQL = `SELECT id, first_name, last_name, email, is_admin, created_at, updated_at FROM users
+WHERE ($1::text IS NULL OR email = $1)
+  AND ($2::text IS NULL
+       OR first_name ILIKE '%' || $2 || '%'
+       OR last_name ILIKE '%' || $2 || '%'
+       OR email ILIKE '%' || $2 || '%')
+  AND ($3::boolean IS NULL OR is_admin = $3)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+ORDER BY created_at DESC, id DESC`
+
+// StreamUsers reads all Users matching filter through a server-side cursor and passes them to fn
+// in batches of batchSize. Limit and offset of filter are ignored. The read-only, repeatable-read
+// transaction gives a consistent snapshot without holding more than one batch in memory.
+func (r *DBUserRepository) StreamUsers(ctx context.Context, filter sqlc.ListUsersParams, batchSize int, fn func([]UserExportRow) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	// Read-only, so rolling back is how the transaction ends in every case
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(ctx, "DECLARE "+userExportCursor+" NO SCROLL CURSOR FOR "+userFilterSQL,
+		filter.Email,
+		filter.Search,
+		filter.IsAdmin,
+		filter.CreatedAfter,
+		filter.CreatedBefore,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to declare export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", batchSize, userExportCursor)
+	batch := make([]UserExportRow, 0, batchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		for rows.Next() {
+			var u UserExportRow
+			if err := rows.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.IsAdmin, &u.CreatedAt, &u.UpdatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, u)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+)
+
+// cursorTx serves FETCH statements from users like a server-side cursor; other methods are not used
+type cursorTx struct {
+	pgx.Tx
+	users      []UserExportRow
+	declared   []any // arguments of DECLARE
+	fetches    []string
+	rolledBack bool
+}
+
+func (tx *cursorTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if !strings.HasPrefix(sql, "DECLARE "+userExportCursor+" NO SCROLL CURSOR FOR SELECT") {
+		return pgconn.CommandTag{}, errors.New("unexpected statement: " + sql)
+	}
+	tx.declared = args
+	return pgconn.NewCommandTag("DECLARE CURSOR"), nil
+}
+
+func (tx *cursorTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.fetches = append(tx.fetches, sql)
+	var n int
+	if _, err := fmt.Sscanf(sql, "FETCH FORWARD %d FROM "+userExportCursor, &n); err != nil {
+		return nil, err
+	}
+	n = min(n, len(tx.users))
+	rows := &exportRows{users: tx.users[:n]}
+	tx.users = tx.users[n:]
+	return rows, nil
+}
+
+func (tx *cursorTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+// exportRows returns users in the column order of userFilterSQL
+type exportRows struct {
+	pgx.Rows
+	users []UserExportRow
+	next  int
+}
+
+func (r *exportRows) Next() bool {
+	r.next++
+	return r.next <= len(r.users)
+}
+
+func (r *exportRows) Scan(dest ...any) error {
+	u := r.users[r.next-1]
+	values := []any{u.ID, u.FirstName, u.LastName, u.Email, u.IsAdmin, u.CreatedAt, u.UpdatedAt}
+	for i, v := range values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *exportRows) Close()     {}
+func (r *exportRows) Err() error { return nil }
+
+// cursorDB begins tx and records the options
+type cursorDB struct {
+	tx   *cursorTx
+	opts pgx.TxOptions
+}
+
+func (db *cursorDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	db.opts = opts
+	return db.tx, nil
+}
+
+func exportUsers(n int) []UserExportRow {
+	users := make([]UserExportRow, n)
+	for i := range users {
+		users[i] = UserExportRow{ID: int64(n - i), Email: fmt.Sprintf("user%d@example.com", i)}
+	}
+	return users
+}
+
+func TestStreamUsers_Batches(t *testing.T) {
+	tests := []struct {
+		name        string
+		users       int
+		batchSize   int
+		wantBatches []int
+		wantFetches int
+	}{
+		{name: "no users", users: 0, batchSize: 2, wantBatches: nil, wantFetches: 1},
+		{name: "partial last batch", users: 5, batchSize: 2, wantBatches: []int{2, 2, 1}, wantFetches: 4},
+		{name: "full last batch", users: 4, batchSize: 2, wantBatches: []int{2, 2}, wantFetches: 3},
+		{name: "one batch", users: 3, batchSize: 10, wantBatches: []int{3}, wantFetches: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := exportUsers(tt.users)
+			db := &cursorDB{tx: &cursorTx{users: users}}
+			repo := &DBUserRepository{db: db}
+
+			var batches []int
+			var got []UserExportRow
+			err := repo.StreamUsers(context.Background(), sqlc.ListUsersParams{}, tt.batchSize, func(rows []UserExportRow) error {
+				batches = append(batches, len(rows))
+				got = append(got, rows...)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("StreamUsers: %v", err)
+			}
+			if !reflect.DeepEqual(batches, tt.wantBatches) {
+				t.Errorf("batch sizes = %v, want %v", batches, tt.wantBatches)
+			}
+			if len(got) != len(users) || (len(users) > 0 && !reflect.DeepEqual(got, users)) {
+				t.Errorf("streamed %v, want %v", got, users)
+			}
+			if len(db.tx.fetches) != tt.wantFetches {
+				t.Errorf("ran %d fetches, want %d", len(db.tx.fetches), tt.wantFetches)
+			}
+			if db.opts.IsoLevel != pgx.RepeatableRead || db.opts.AccessMode != pgx.ReadOnly {
+				t.Errorf("transaction options = %+v, want a read-only repeatable-read snapshot", db.opts)
+			}
+			if !db.tx.rolledBack {
+				t.Error("the transaction was not ended")
+			}
+		})
+	}
+}
+
+func TestStreamUsers_StopsOnCallbackError(t *testing.T) {
+	db := &cursorDB{tx: &cursorTx{users: exportUsers(6)}}
+	repo := &DBUserRepository{db: db}
+	errClientGone := errors.New("client went away")
+
+	calls := 0
+	err := repo.StreamUsers(context.Background(), sqlc.ListUsersParams{}, 2, func([]UserExportRow) error {
+		calls++
+		return errClientGone
+	})
+	if !errors.Is(err, errClientGone) {
+		t.Errorf("StreamUsers = %v, want the error of the callback", err)
+	}
+	if calls != 1 || len(db.tx.fetches) != 1 {
+		t.Errorf("%d callbacks after %d fetches, want the stream to stop after the first batch", calls, len(db.tx.fetches))
+	}
+	if !db.tx.rolledBack {
+		t.Error("the transaction was not ended")
+	}
+}
+
+func TestStreamUsers_PassesFilter(t *testing.T) {
+	db := &cursorDB{tx: &cursorTx{}}
+	repo := &DBUserRepository{db: db}
+	filter := sqlc.ListUsersParams{
+		Search:  pgtype.Text{String: "smith", Valid: true},
+		IsAdmin: pgtype.Bool{Bool: true, Valid: true},
+	}
+
+	if err := repo.StreamUsers(context.Background(), filter, 10, func([]UserExportRow) error { return nil }); err != nil {
+		t.Fatalf("StreamUsers: %v", err)
+	}
+	want := []any{filter.Email, filter.Search, filter.IsAdmin, filter.CreatedAfter, filter.CreatedBefore}
+	if !reflect.DeepEqual(db.tx.declared, want) {
+		t.Errorf("cursor arguments = %v, want %v", db.tx.declared, want)
+	}
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/privacy"
 	"github.com/yourusername/yourprojectname/internal/util"
 )
 
@@ -13,19 +15,31 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error)
 	GetUserByID(ctx context.Context, id int64) (sqlc.User, error)
 	GetUserByEmail(ctx context.Context, email string) (sqlc.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]sqlc.User, error)
+	GetUsersByEmails(ctx context.Context, emails []string) ([]sqlc.User, error)
 	ListUsers(ctx context.Context, arg sqlc.ListUsersParams) ([]sqlc.User, error)
 	UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (sqlc.User, error)
+	UpdateUserAvatar(ctx context.Context, arg sqlc.UpdateUserAvatarParams) (sqlc.User, error)
+	SetUserAdmin(ctx context.Context, arg sqlc.SetUserAdminParams) (sqlc.User, error)
 	DeleteUser(ctx context.Context, id int64) error
+	CopyUsers(ctx context.Context, arg []sqlc.CopyUsersParams) (int64, error)
+	ListExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	ListUserHistory(ctx context.Context, userID int64) ([]sqlc.UserHistory, error)
+	GetUserHistoryAsOf(ctx context.Context, arg sqlc.GetUserHistoryAsOfParams) (sqlc.UserHistory, error)
+	StreamUsers(ctx context.Context, filter sqlc.ListUsersParams, batchSize int, fn func([]UserExportRow) error) error
+	privacy.Hook
 }
 
 // DBUserRepository takes sqlc.Querier to create an instance
+// db is used for statements sqlc cannot express, such as cursors
 type DBUserRepository struct {
-	q sqlc.Querier
+	q  sqlc.Querier
+	db TxBeginner
 }
 
 // NewDBUserRepository creates a new instance of DBUserRepository
-func NewDBUserRepository(querier sqlc.Querier) UserRepository {
-	return &DBUserRepository{q: querier}
+func NewDBUserRepository(querier sqlc.Querier, db TxBeginner) UserRepository {
+	return &DBUserRepository{q: querier, db: db}
 }
 
 // CreateUser creates a new user in DB
@@ -37,22 +51,40 @@ func (r *DBUserRepository) CreateUser(ctx context.Context, arg sqlc.CreateUserPa
 	}
 	arg.HashedPassword = hashedPassword
 
-	return r.q.CreateUser(ctx, arg)
+	user, err := r.q.CreateUser(ctx, arg)
+	return user, translateError(err, "user")
 }
 
 // GetUserByID retrieves a User by id
 func (r *DBUserRepository) GetUserByID(ctx context.Context, id int64) (sqlc.User, error) {
-	return r.q.GetUserByID(ctx, id)
+	user, err := r.q.GetUserByID(ctx, id)
+	return user, translateError(err, "user")
 }
 
 // GetUserByEmail retrieves a User by email
 func (r *DBUserRepository) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
-	return r.q.GetUserByEmail(ctx, email)
+	user, err := r.q.GetUserByEmail(ctx, email)
+	return user, translateError(err, "user")
+}
+
+// GetUsersByIDs retrieves the Users with the given ids in a single query
+// Rows come back in no particular order and unknown ids are simply absent
+func (r *DBUserRepository) GetUsersByIDs(ctx context.Context, ids []int64) ([]sqlc.User, error) {
+	users, err := r.q.GetUsersByIDs(ctx, ids)
+	return users, translateError(err, "user")
+}
+
+// GetUsersByEmails retrieves the Users with the given emails in a single query
+// Rows come back in no particular order and unknown emails are simply absent
+func (r *DBUserRepository) GetUsersByEmails(ctx context.Context, emails []string) ([]sqlc.User, error) {
+	users, err := r.q.GetUsersByEmails(ctx, emails)
+	return users, translateError(err, "user")
 }
 
 // ListUsers retrieves a list of Users
 func (r *DBUserRepository) ListUsers(ctx context.Context, arg sqlc.ListUsersParams) ([]sqlc.User, error) {
-	return r.q.ListUsers(ctx, arg)
+	users, err := r.q.ListUsers(ctx, arg)
+	return users, translateError(err, "user")
 }
 
 // UpdateUser updates a User
@@ -72,10 +104,99 @@ func (r *DBUserRepository) UpdateUser(ctx context.Context, arg sqlc.UpdateUserPa
 		}
 	}
 
-	return r.q.UpdateUser(ctx, arg)
+	user, err := r.q.UpdateUser(ctx, arg)
+	return user, translateError(err, "user")
+}
+
+// UpdateUserAvatar sets or clears the avatar blob key of a User
+func (r *DBUserRepository) UpdateUserAvatar(ctx context.Context, arg sqlc.UpdateUserAvatarParams) (sqlc.User, error) {
+	user, err := r.q.UpdateUserAvatar(ctx, arg)
+	return user, translateError(err, "user")
+}
+
+// SetUserAdmin grants or revokes the admin role of a User
+func (r *DBUserRepository) SetUserAdmin(ctx context.Context, arg sqlc.SetUserAdminParams) (sqlc.User, error) {
+	user, err := r.q.SetUserAdmin(ctx, arg)
+	return user, translateError(err, "user")
 }
 
 // DeleteUser deletes a User by id
 func (r *DBUserRepository) DeleteUser(ctx context.Context, id int64) error {
-	return r.q.DeleteUser(ctx, id)
+	return translateError(r.q.DeleteUser(ctx, id), "user")
+}
+
+// CopyUsers bulk-inserts Users with the COPY protocol
+// Unlike CreateUser, passwords must already be hashed
+func (r *DBUserRepository) CopyUsers(ctx context.Context, arg []sqlc.CopyUsersParams) (int64, error) {
+	n, err := r.q.CopyUsers(ctx, arg)
+	return n, translateError(err, "user")
+}
+
+// ListExistingEmails returns the subset of emails that already belong to a User
+func (r *DBUserRepository) ListExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	emails, err := r.q.ListExistingEmails(ctx, emails)
+	return emails, translateError(err, "user")
+}
+
+// ListUserHistory retrieves the prior versions of a User, newest first
+func (r *DBUserRepository) ListUserHistory(ctx context.Context, userID int64) ([]sqlc.UserHistory, error) {
+	history, err := r.q.ListUserHistory(ctx, userID)
+	return history, translateError(err, "user")
+}
+
+// GetUserHistoryAsOf retrieves the prior version of a User that was current at a point in time
+func (r *DBUserRepository) GetUserHistoryAsOf(ctx context.Context, arg sqlc.GetUserHistoryAsOfParams) (sqlc.UserHistory, error) {
+	version, err := r.q.GetUserHistoryAsOf(ctx, arg)
+	return version, translateError(err, "user")
+}
+
+// PrivacyHookName identifies the users table in data exports
+func (r *DBUserRepository) PrivacyHookName() string {
+	return "users"
+}
+
+// ExportUserData exports the profile of a User and its prior versions, without the password hash
+func (r *DBUserRepository) ExportUserData(ctx context.Context, userID int64, w privacy.ExportWriter) error {
+	user, err := r.q.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = w.WriteJSON("profile.json", map[string]any{
+		"id":         user.ID,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"email":      user.Email,
+		"avatar_key": user.AvatarKey,
+		"locale":     user.Locale,
+		"created_at": user.CreatedAt,
+		"updated_at": user.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	history, err := r.q.ListUserHistory(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return w.WriteJSON("history.json", history)
+}
+
+// EraseUserData deletes the User row and leaves a tombstone
+// The history is deleted in the same transaction, after the trigger recorded the deleted version
+func (r *DBUserRepository) EraseUserData(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	q := sqlc.New(tx)
+	if err := q.EraseUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := q.DeleteUserHistory(ctx, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/handler"
+)
+
+// SetupUserImportRoutes configures the admin-only user import within a given router group.
+// The middlewares must authenticate the caller and require admin privileges. requireImport guards the user import.
+func SetupUserImportRoutes(apiGroup *gin.RouterGroup, userImportHandler *handler.UserImportHandler, requireImport gin.HandlerFunc, middlewares ...gin.HandlerFunc) {
+	adminRoutes := apiGroup.Group("/admin", middlewares...)
+	{
+		adminRoutes.POST("/users/import", requireImport, userImportHandler.ImportUsers)
+	}
+}
+
+// SetupAuditRoutes configures the admin-only audit log query within a given router group.
+// The middlewares must authenticate the caller and require admin privileges.
+func SetupAuditRoutes(apiGroup *gin.RouterGroup, auditHandler *handler.AuditHandler, middlewares ...gin.HandlerFunc) {
+	adminRoutes := apiGroup.Group("/admin", middlewares...)
+	{
+		adminRoutes.GET("/audit-events", auditHandler.ListEvents)
+	}
+}
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/handler"
+)
+
+// SetupBlobRoutes configures the download route for locally stored blobs within a given router group.
+func SetupBlobRoutes(apiGroup *gin.RouterGroup, blobHandler *handler.BlobHandler) {
+	apiGroup.GET("/blobs/*key", blobHandler.GetBlob)
+}
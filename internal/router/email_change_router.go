@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/handler"
+)
+
+// SetupEmailChangeRoutes configures the routes for the email change flow within a given router group.
+func SetupEmailChangeRoutes(apiGroup *gin.RouterGroup, emailChangeHandler *handler.EmailChangeHandler) {
+	apiGroup.POST("/users/:id/email-change", emailChangeHandler.RequestEmailChange)
+
+	emailChangeRoutes := apiGroup.Group("/email-changes")
+	{
+		emailChangeRoutes.POST("/confirm", emailChangeHandler.ConfirmEmailChange)
+		emailChangeRoutes.POST("/revert", emailChangeHandler.RevertEmailChange)
+	}
+}
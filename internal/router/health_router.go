@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/handler"
+)
+
+// SetupHealthRoutes configures the probe routes at the root, outside the versioned API and its rate limit.
+func SetupHealthRoutes(router gin.IRoutes, healthHandler *handler.HealthHandler) {
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/startupz", healthHandler.Started)
+}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/handler"
+)
+
+// SetupPrivacyRoutes configures the GDPR routes of the current user within a given router group.
+// authMiddleware must authenticate the caller, as every route acts on "me". requireExport guards the data export.
+func SetupPrivacyRoutes(apiGroup *gin.RouterGroup, privacyHandler *handler.PrivacyHandler, authMiddleware, requireExport gin.HandlerFunc) {
+	meRoutes := apiGroup.Group("/users/me", authMiddleware)
+	{
+		meRoutes.POST("/export", requireExport, privacyHandler.RequestExport)
+		meRoutes.GET("/exports/:id", requireExport, privacyHandler.GetExport)
+		meRoutes.POST("/erase", privacyHandler.EraseUser)
+	}
+}
@@ -1,19 +1,46 @@
 package router
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/yourusername/yourprojectname/internal/apperror"
 	"github.com/yourusername/yourprojectname/internal/handler"
 )
 
 // SetupUserRoutes configures the routes for user-related actions within a given router group.
-func SetupUserRoutes(apiGroup *gin.RouterGroup, userHandler *handler.UserHandler) {
+// requireAuth guards the routes that modify a user or expose its history; requireAdmin additionally
+// guards the routes exposing many users at once (listing, exports and batch lookups). optionalAuth lets the public
+// user lookup recognize callers allowed to see past versions. requireAvatars guards avatar uploads, which also require authentication.
+func SetupUserRoutes(apiGroup *gin.RouterGroup, userHandler *handler.UserHandler, userExportHandler *handler.UserExportHandler, optionalAuth, requireAuth, requireAdmin, requireAvatars gin.HandlerFunc) {
+	// Gin cannot escape a literal colon, so "/users:batchGet" registers a parameter right after "/users"
+	// and customMethods rejects every value other than the known methods.
+	apiGroup.POST("/users:method", requireAuth, requireAdmin, customMethods(map[string]gin.HandlerFunc{
+		"batchGet": userHandler.BatchGetUsers,
+	}))
+
 	userRoutes := apiGroup.Group("/users")
 	{
 		userRoutes.POST("", userHandler.CreateUser)
-		userRoutes.GET("/:id", userHandler.GetUserByID)
-		// Future routes like ListUsers, UpdateUser, DeleteUser would go here.
-		// userRoutes.GET("", userHandler.ListUsers)
-		// userRoutes.PUT("/:id", userHandler.UpdateUser)
-		// userRoutes.DELETE("/:id", userHandler.DeleteUser)
+		userRoutes.GET("", requireAuth, requireAdmin, userHandler.ListUsers)
+		userRoutes.GET("/export", requireAuth, requireAdmin, userExportHandler.ExportUsers)
+		userRoutes.GET("/:id", optionalAuth, userHandler.GetUserByID)
+		userRoutes.GET("/:id/history", requireAuth, userHandler.GetUserHistory)
+		userRoutes.PUT("/:id/avatar", requireAvatars, requireAuth, userHandler.UploadAvatar)
+		userRoutes.PUT("/:id", requireAuth, userHandler.UpdateUser)
+		userRoutes.DELETE("/:id", requireAuth, userHandler.DeleteUser)
+	}
+}
+
+// customMethods dispatches Google API style custom methods ("/resource:method") by name
+func customMethods(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, isMethod := strings.CutPrefix(c.Param("method"), ":")
+		h, ok := methods[name]
+		if !isMethod || !ok {
+			_ = c.Error(apperror.New(apperror.NotFound, "not found"))
+			return
+		}
+		h(c)
 	}
 }
@@ -0,0 +1,25 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvSecretProvider reads secrets from environment variables; the reference is the variable name.
+// Useful to point a setting at a variable injected under another name.
+type EnvSecretProvider struct{}
+
+// NewEnvSecretProvider creates a new instance of EnvSecretProvider
+func NewEnvSecretProvider() *EnvSecretProvider {
+	return &EnvSecretProvider{}
+}
+
+// Fetch returns the value of the environment variable ref
+func (p *EnvSecretProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("$%s: %w", ref, ErrSecretNotFound)
+	}
+	return value, nil
+}
@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FileSecretProvider reads secrets from files, such as Kubernetes or Docker Swarm secret mounts;
+// the reference is the file path. A trailing newline is removed.
+// Mounted secrets are updated in place when rotated, so refreshing picks up the new value.
+type FileSecretProvider struct{}
+
+// NewFileSecretProvider creates a new instance of FileSecretProvider
+func NewFileSecretProvider() *FileSecretProvider {
+	return &FileSecretProvider{}
+}
+
+// Fetch returns the content of the file ref
+func (p *FileSecretProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", ref, ErrSecretNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,17 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSecretNotFound indicates that a provider has no secret under the requested reference.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider defines methods for fetching secrets from an external source.
+// Config values of the form "<scheme>:<ref>", e.g. "vault:secret/data/app#secret_key", are fetched
+// from the provider registered under scheme, see Store.
+type SecretProvider interface {
+	// Fetch returns the current value of the secret identified by ref
+	Fetch(ctx context.Context, ref string) (string, error)
+}
@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store resolves secret references through the registered providers and keeps the current values.
+// Values are refreshed by Refresh or Run; readers always see the latest successfully fetched value.
+type Store struct {
+	providers map[string]SecretProvider
+
+	mu     sync.RWMutex
+	refs   map[string]string // reference by key
+	values map[string]string // current value by key
+}
+
+// NewStore creates a store resolving "<scheme>:<ref>" references through providers, keyed by scheme
+func NewStore(providers map[string]SecretProvider) *Store {
+	return &Store{
+		providers: providers,
+		refs:      make(map[string]string),
+		values:    make(map[string]string),
+	}
+}
+
+// IsReference reports whether value has the form "<scheme>:<ref>" for a registered scheme
+func (s *Store) IsReference(value string) bool {
+	scheme, ref, ok := strings.Cut(value, ":")
+	_, known := s.providers[scheme]
+	return ok && known && ref != ""
+}
+
+// Add fetches the secret referenced by value and keeps it refreshed under key.
+// It returns the fetched value.
+func (s *Store) Add(ctx context.Context, key, value string) (string, error) {
+	secret, err := s.fetch(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs[key] = value
+	s.values[key] = secret
+	return secret, nil
+}
+
+// Get returns the current value of the secret stored under key, or "" if there is none
+func (s *Store) Get(key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// Has reports whether a secret is stored under key
+func (s *Store) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.refs[key]
+	return ok
+}
+
+// Refresh fetches every secret again and returns the keys whose value changed.
+// A secret that cannot be fetched keeps its previous value; all failures are joined in the error.
+func (s *Store) Refresh(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	refs := make(map[string]string, len(s.refs))
+	for key, ref := range s.refs {
+		refs[key] = ref
+	}
+	s.mu.RUnlock()
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changed []string
+	var errs []error
+	for _, key := range keys {
+		secret, err := s.fetch(ctx, refs[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		s.mu.Lock()
+		if s.values[key] != secret {
+			s.values[key] = secret
+			changed = append(changed, key)
+		}
+		s.mu.Unlock()
+	}
+	return changed, errors.Join(errs...)
+}
+
+// Run refreshes the secrets every interval until ctx is cancelled.
+// onRefresh is called after each refresh with the changed keys and the error of Refresh, if any.
+func (s *Store) Run(ctx context.Context, interval time.Duration, onRefresh func(changed []string, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.Refresh(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if onRefresh != nil {
+				onRefresh(changed, err)
+			}
+		}
+	}
+}
+
+func (s *Store) fetch(ctx context.Context, value string) (string, error) {
+	scheme, ref, _ := strings.Cut(value, ":")
+	provider, ok := s.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("unknown secret provider %q", scheme)
+	}
+	secret, err := provider.Fetch(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s secret provider: %w", scheme, err)
+	}
+	return secret, nil
+}
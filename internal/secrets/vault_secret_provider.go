@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultConfig holds the connection settings for a HashiCorp Vault compatible HTTP API
+type VaultConfig struct {
+	Addr      string // e.g. "https://vault.example.com:8200"
+	Token     string
+	Namespace string       // Vault Enterprise namespace, optional
+	Client    *http.Client // defaults to a client with a 10 second timeout
+}
+
+// VaultSecretProvider reads secrets from the Vault HTTP API, KV version 1 or 2.
+// References are "<path>#<field>", e.g. "secret/data/app#secret_key" reads the field secret_key
+// of the KV v2 secret app on the mount secret.
+type VaultSecretProvider struct {
+	cfg VaultConfig
+}
+
+// NewVaultSecretProvider creates a new instance of VaultSecretProvider
+func NewVaultSecretProvider(cfg VaultConfig) *VaultSecretProvider {
+	cfg.Addr = strings.TrimRight(cfg.Addr, "/")
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &VaultSecretProvider{cfg: cfg}
+}
+
+// vaultResponse is the envelope of a Vault read; KV v2 nests the secret in data.data
+type vaultResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []string       `json:"errors"`
+}
+
+// Fetch reads the field of the secret at path
+func (p *VaultSecretProvider) Fetch(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected <path>#<field>", ref)
+	}
+	if p.cfg.Addr == "" {
+		return "", errors.New("no vault address configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Addr+"/v1/"+escapePath(strings.Trim(path, "/")), nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	resp, err := p.cfg.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to reach vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body vaultResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("unable to decode vault response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%s: %w", path, ErrSecretNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%s: status %d: %s", path, resp.StatusCode, strings.Join(body.Errors, "; "))
+	}
+
+	data := body.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%s#%s: %w", path, field, ErrSecretNotFound)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,190 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testVaultToken = "s.test-token"
+
+// fakeVault serves secrets by path like the Vault HTTP API, rejecting requests without the test token
+type fakeVault struct {
+	mu        sync.Mutex
+	secrets   map[string]map[string]any // response data by path below /v1/
+	namespace string                    // of the last request
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *VaultSecretProvider) {
+	t.Helper()
+	f := &fakeVault{secrets: make(map[string]map[string]any)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, NewVaultSecretProvider(VaultConfig{Addr: srv.URL + "/", Token: testVaultToken, Namespace: "team-a"})
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.namespace = r.Header.Get("X-Vault-Namespace")
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("X-Vault-Token") != testVaultToken {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	data, ok := f.secrets[strings.TrimPrefix(r.URL.Path, "/v1/")]
+	if r.Method != http.MethodGet || !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// putV1 stores a KV v1 secret, whose fields are the response data
+func (f *fakeVault) putV1(path string, fields map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[path] = fields
+}
+
+// putV2 stores a KV v2 secret, whose fields are nested in data.data next to data.metadata
+func (f *fakeVault) putV2(path string, fields map[string]any, version int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.secrets[path] = map[string]any{"data": fields, "metadata": map[string]any{"version": version}}
+}
+
+func TestVaultSecretProvider_KVv2(t *testing.T) {
+	vault, provider := newFakeVault(t)
+	vault.putV2("secret/data/app", map[string]any{"secret_key": "s3cr3t", "port": 5432}, 1)
+
+	got, err := provider.Fetch(context.Background(), "secret/data/app#secret_key")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got != "s3cr3t" {
+		t.Errorf("Fetch = %q, want %q", got, "s3cr3t")
+	}
+	if vault.namespace != "team-a" {
+		t.Errorf("namespace header = %q, want %q", vault.namespace, "team-a")
+	}
+
+	// Values that are not strings are formatted
+	got, err = provider.Fetch(context.Background(), "/secret/data/app/#port")
+	if err != nil {
+		t.Fatalf("Fetch of a number: %v", err)
+	}
+	if got != "5432" {
+		t.Errorf("Fetch of a number = %q, want %q", got, "5432")
+	}
+}
+
+func TestVaultSecretProvider_KVv1(t *testing.T) {
+	vault, provider := newFakeVault(t)
+	// A v1 field named data is not mistaken for the v2 envelope, which always has metadata
+	vault.putV1("kv/app", map[string]any{"db_password": "hunter2", "data": map[string]any{"nested": true}})
+
+	got, err := provider.Fetch(context.Background(), "kv/app#db_password")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got != "hunter2" {
+		t.Errorf("Fetch = %q, want %q", got, "hunter2")
+	}
+}
+
+func TestVaultSecretProvider_NotFound(t *testing.T) {
+	vault, provider := newFakeVault(t)
+	vault.putV2("secret/data/app", map[string]any{"secret_key": "s3cr3t"}, 1)
+
+	tests := map[string]string{
+		"missing secret": "secret/data/other#secret_key",
+		"missing field":  "secret/data/app#api_key",
+	}
+	for name, ref := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := provider.Fetch(context.Background(), ref)
+			if !errors.Is(err, ErrSecretNotFound) {
+				t.Errorf("Fetch(%q) = %v, want ErrSecretNotFound", ref, err)
+			}
+		})
+	}
+}
+
+func TestVaultSecretProvider_BadToken(t *testing.T) {
+	vault, _ := newFakeVault(t)
+	vault.putV2("secret/data/app", map[string]any{"secret_key": "s3cr3t"}, 1)
+	srv := httptest.NewServer(vault)
+	t.Cleanup(srv.Close)
+	provider := NewVaultSecretProvider(VaultConfig{Addr: srv.URL, Token: "s.revoked"})
+
+	_, err := provider.Fetch(context.Background(), "secret/data/app#secret_key")
+	if err == nil {
+		t.Fatal("Fetch with a bad token succeeded")
+	}
+	if errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Fetch with a bad token = %v, want an error other than ErrSecretNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "status 403") || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Fetch with a bad token = %v, want the status and the errors of vault", err)
+	}
+}
+
+func TestVaultSecretProvider_InvalidReference(t *testing.T) {
+	_, provider := newFakeVault(t)
+
+	for _, ref := range []string{"secret/data/app", "secret/data/app#", "#secret_key"} {
+		if _, err := provider.Fetch(context.Background(), ref); err == nil || errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("Fetch(%q) = %v, want an invalid reference error", ref, err)
+		}
+	}
+}
+
+func TestStore_RefreshPicksUpRotatedVaultSecret(t *testing.T) {
+	vault, provider := newFakeVault(t)
+	vault.putV2("secret/data/app", map[string]any{"secret_key": "old"}, 1)
+	vault.putV1("kv/db", map[string]any{"password": "unchanged"})
+	store := NewStore(map[string]SecretProvider{"vault": provider})
+	ctx := context.Background()
+
+	if _, err := store.Add(ctx, "secret_key", "vault:secret/data/app#secret_key"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := store.Add(ctx, "db.password", "vault:kv/db#password"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	vault.putV2("secret/data/app", map[string]any{"secret_key": "new"}, 2)
+	changed, err := store.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if !slices.Equal(changed, []string{"secret_key"}) {
+		t.Errorf("Refresh changed %v, want [secret_key]", changed)
+	}
+	if got := store.Get("secret_key"); got != "new" {
+		t.Errorf("Get after rotation = %q, want %q", got, "new")
+	}
+
+	// A secret that cannot be fetched keeps its value
+	vault.putV1("kv/db", map[string]any{})
+	changed, err = store.Refresh(ctx)
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Refresh after removing a field = %v, want ErrSecretNotFound", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("Refresh changed %v, want nothing", changed)
+	}
+	if got := store.Get("db.password"); got != "unchanged" {
+		t.Errorf("Get after a failed refresh = %q, want %q", got, "unchanged")
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/audit"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// auditPartitionsAhead is how many future monthly partitions are kept ready
+const auditPartitionsAhead = 3
+
+// Partition maintenance runs daily; a failed run, e.g. before the migrations are applied, is retried sooner.
+// Each run is bounded, so a hanging database does not keep the job from stopping.
+const (
+	auditMaintenanceInterval = 24 * time.Hour
+	auditMaintenanceRetry    = time.Minute
+	auditMaintenanceTimeout  = time.Minute
+)
+
+// Audit actions recorded for users.
+const (
+	AuditUserCreate       = "user.create"
+	AuditUserUpdate       = "user.update"
+	AuditUserDelete       = "user.delete"
+	AuditUserAvatar       = "user.avatar_update"
+	AuditUserEmailConfirm = "user.email_change_confirm"
+	AuditUserEmailRevert  = "user.email_change_revert"
+	AuditUserErase        = "user.erase"
+	AuditUserImport       = "user.import"
+)
+
+// AuditEvent describes a change to record. Before and After are snapshots of the target,
+// nil on creation and deletion respectively; only the changed fields are stored.
+// Details are stored as-is for events that are not about a single record.
+type AuditEvent struct {
+	Action     string
+	TargetType string
+	TargetID   int64
+	Before     any
+	After      any
+	Details    map[string]any
+}
+
+// AuditLogger records who changed what. The actor, request ID and IP are taken from the
+// audit.Metadata in ctx. Recording happens after the change is committed, so failures are
+// logged rather than returned.
+type AuditLogger interface {
+	Record(ctx context.Context, event AuditEvent)
+}
+
+// AuditService defines the interface for the audit log, including queries and retention.
+type AuditService interface {
+	AuditLogger
+	ListEvents(ctx context.Context, params sqlc.ListAuditEventsParams) ([]sqlc.AuditEvent, error)
+	// MaintainPartitions creates upcoming monthly partitions and drops those past retention.
+	MaintainPartitions(ctx context.Context) error
+	// Run maintains the partitions right away and then once a day until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type auditServiceImpl struct {
+	auditRepo repository.AuditEventRepository
+	retention time.Duration
+}
+
+// NewAuditService creates a new instance of AuditService.
+// Events older than retention are dropped a whole month at a time; 0 keeps them forever.
+func NewAuditService(auditRepo repository.AuditEventRepository, retention time.Duration) AuditService {
+	return &auditServiceImpl{
+		auditRepo: auditRepo,
+		retention: retention,
+	}
+}
+
+// Record stores an event with the redacted diff of its before and after snapshots.
+func (s *auditServiceImpl) Record(ctx context.Context, event AuditEvent) {
+	md := audit.MetadataFrom(ctx)
+
+	changes, err := s.changes(event)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to build audit diff", eventAttrs(event), slog.Any("error", err))
+		changes = []byte("{}")
+	}
+
+	params := sqlc.CreateAuditEventParams{
+		ActorID:    pgtype.Int8{Int64: md.ActorID, Valid: md.ActorID != 0},
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   pgtype.Int8{Int64: event.TargetID, Valid: event.TargetID != 0},
+		Changes:    changes,
+		RequestID:  pgtype.Text{String: md.RequestID, Valid: md.RequestID != ""},
+		Ip:         pgtype.Text{String: md.IP, Valid: md.IP != ""},
+	}
+	// The change is already committed; a client disconnecting must not lose its audit trail
+	if _, err := s.auditRepo.CreateAuditEvent(context.WithoutCancel(ctx), params); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to record audit event", eventAttrs(event), slog.Any("error", err))
+	}
+}
+
+// eventAttrs identifies an event in log records
+func eventAttrs(event AuditEvent) slog.Attr {
+	return slog.Group("audit_event",
+		slog.String("action", event.Action),
+		slog.String("target_type", event.TargetType),
+		slog.Int64("target_id", event.TargetID),
+	)
+}
+
+func (s *auditServiceImpl) changes(event AuditEvent) ([]byte, error) {
+	if event.Details != nil {
+		return json.Marshal(event.Details)
+	}
+	if event.Before == nil && event.After == nil {
+		return []byte("{}"), nil
+	}
+	diff, err := audit.Diff(event.Before, event.After)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(diff)
+}
+
+// ListEvents retrieves a page of events matching the filters in params, newest first.
+func (s *auditServiceImpl) ListEvents(ctx context.Context, params sqlc.ListAuditEventsParams) ([]sqlc.AuditEvent, error) {
+	return s.auditRepo.ListAuditEvents(ctx, params)
+}
+
+// MaintainPartitions creates upcoming monthly partitions and drops those past retention.
+func (s *auditServiceImpl) MaintainPartitions(ctx context.Context) error {
+	now := time.Now()
+	if err := s.auditRepo.EnsureAuditPartitions(ctx, now, auditPartitionsAhead); err != nil {
+		return err
+	}
+	if s.retention <= 0 {
+		return nil
+	}
+	dropped, err := s.auditRepo.DropAuditPartitionsBefore(ctx, now.Add(-s.retention))
+	for _, name := range dropped {
+		logging.FromContext(ctx).InfoContext(ctx, "Dropped audit partition past retention", slog.String("partition", name))
+	}
+	return err
+}
+
+// Run maintains the partitions right away and then once a day until ctx is cancelled.
+// Events recorded before the first run land in the default partition and are moved out of it then.
+func (s *auditServiceImpl) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			next := auditMaintenanceInterval
+			runCtx, cancel := context.WithTimeout(ctx, auditMaintenanceTimeout)
+			if err := s.MaintainPartitions(runCtx); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to maintain audit partitions", slog.Any("error", err))
+				next = auditMaintenanceRetry
+			}
+			cancel()
+			timer.Reset(next)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/storage"
+	"github.com/yourusername/yourprojectname/internal/util"
+)
+
+// avatarVariants lists the renditions generated for every upload.
+// The first entry is the one whose key is stored on the user row.
+var avatarVariants = []util.ImageVariant{
+	{Name: "original", MaxSize: 1024},
+	{Name: "medium", Size: 256},
+	{Name: "small", Size: 64},
+}
+
+// AvatarService defines the interface for avatar uploads and download URLs.
+type AvatarService interface {
+	UploadAvatar(ctx context.Context, userID int64, data []byte) (sqlc.User, error)
+	AvatarURLs(ctx context.Context, user sqlc.User) (map[string]string, error)
+	MaxBytes() int64
+	privacy.Hook
+}
+
+type avatarServiceImpl struct {
+	userRepo    repository.UserRepository
+	blobStore   storage.BlobStore
+	auditLogger AuditLogger
+	maxBytes    int64
+	urlTTL      time.Duration
+}
+
+// NewAvatarService creates a new instance of AvatarService.
+func NewAvatarService(userRepo repository.UserRepository, blobStore storage.BlobStore, auditLogger AuditLogger, maxBytes int64, urlTTL time.Duration) AvatarService {
+	return &avatarServiceImpl{
+		userRepo:    userRepo,
+		blobStore:   blobStore,
+		auditLogger: auditLogger,
+		maxBytes:    maxBytes,
+		urlTTL:      urlTTL,
+	}
+}
+
+// MaxBytes returns the largest accepted upload size.
+func (s *avatarServiceImpl) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// UploadAvatar validates and processes an image, stores all variants and points the user at them.
+// Previously stored variants are removed once the user row has been updated.
+func (s *avatarServiceImpl) UploadAvatar(ctx context.Context, userID int64, data []byte) (sqlc.User, error) {
+	if int64(len(data)) > s.maxBytes {
+		return sqlc.User{}, util.ErrImageTooLarge
+	}
+
+	// Fail fast with the repository error (e.g. no rows) before doing any image work
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+
+	images, err := util.ProcessImage(data, avatarVariants)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+
+	// A random path segment per upload keeps URLs cache-friendly and avoids overwriting live files
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return sqlc.User{}, fmt.Errorf("failed to generate avatar key: %w", err)
+	}
+	prefix := fmt.Sprintf("avatars/%d/%s", userID, hex.EncodeToString(nonce))
+
+	storedKeys := make([]string, 0, len(images))
+	for _, img := range images {
+		key := prefix + "/" + img.Name + img.Extension
+		if err := s.blobStore.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+			s.deleteKeys(storedKeys)
+			return sqlc.User{}, fmt.Errorf("failed to store avatar: %w", err)
+		}
+		storedKeys = append(storedKeys, key)
+	}
+
+	updated, err := s.userRepo.UpdateUserAvatar(ctx, sqlc.UpdateUserAvatarParams{
+		AvatarKey: pgtype.Text{String: storedKeys[0], Valid: true},
+		ID:        userID,
+	})
+	if err != nil {
+		s.deleteKeys(storedKeys)
+		return sqlc.User{}, err
+	}
+
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserAvatar, TargetType: "user", TargetID: userID, Before: user, After: updated})
+
+	if user.AvatarKey.Valid {
+		s.deleteKeys(variantKeys(user.AvatarKey.String))
+	}
+	return updated, nil
+}
+
+// AvatarURLs returns a signed, time-limited URL per variant, or nil if the user has no avatar.
+func (s *avatarServiceImpl) AvatarURLs(ctx context.Context, user sqlc.User) (map[string]string, error) {
+	if !user.AvatarKey.Valid {
+		return nil, nil
+	}
+	keys := variantKeys(user.AvatarKey.String)
+	urls := make(map[string]string, len(keys))
+	for i, key := range keys {
+		u, err := s.blobStore.SignedURL(ctx, key, s.urlTTL)
+		if err != nil {
+			return nil, err
+		}
+		urls[avatarVariants[i].Name] = u
+	}
+	return urls, nil
+}
+
+// PrivacyHookName identifies avatar files in data exports.
+func (s *avatarServiceImpl) PrivacyHookName() string {
+	return "avatars"
+}
+
+// ExportUserData adds the stored avatar variants to a data export.
+func (s *avatarServiceImpl) ExportUserData(ctx context.Context, userID int64, w privacy.ExportWriter) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil || !user.AvatarKey.Valid {
+		return err
+	}
+	for _, key := range variantKeys(user.AvatarKey.String) {
+		blob, err := s.blobStore.Get(ctx, key)
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = w.WriteFile(path.Base(key), blob)
+		blob.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EraseUserData deletes the stored avatar variants. The user row itself is erased by the users hook.
+func (s *avatarServiceImpl) EraseUserData(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, apperror.NotFound) {
+		return nil // already erased
+	}
+	if err != nil || !user.AvatarKey.Valid {
+		return err
+	}
+	for _, key := range variantKeys(user.AvatarKey.String) {
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteKeys removes blobs on a best-effort basis; orphans are logged rather than failing the request
+func (s *avatarServiceImpl) deleteKeys(keys []string) {
+	for _, key := range keys {
+		if err := s.blobStore.Delete(context.Background(), key); err != nil {
+			slog.Error("Failed to delete avatar blob", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// variantKeys derives all variant keys from the stored key of the first variant
+func variantKeys(storedKey string) []string {
+	dir, ext := path.Dir(storedKey), path.Ext(storedKey)
+	if !strings.HasPrefix(path.Base(storedKey), avatarVariants[0].Name) {
+		return []string{storedKey}
+	}
+	keys := make([]string, len(avatarVariants))
+	for i, v := range avatarVariants {
+		keys[i] = dir + "/" + v.Name + ext
+	}
+	return keys
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/audit"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/mailer"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/util"
+)
+
+var (
+	// ErrInvalidCredentials indicates that the supplied password does not match.
+	ErrInvalidCredentials = apperror.New(apperror.Unauthorized, "current password is incorrect")
+	// ErrEmailTaken indicates that another user already owns the requested email.
+	ErrEmailTaken = apperror.New(apperror.Conflict, "email already in use")
+	// ErrEmailUnchanged indicates that the requested email equals the current one.
+	ErrEmailUnchanged = apperror.New(apperror.Validation, "new email must differ from the current email")
+	// ErrEmailChangeInProgress indicates that a concurrent request for the same user won the race.
+	ErrEmailChangeInProgress = apperror.New(apperror.Conflict, "another email change is in progress")
+	// ErrInvalidToken indicates that a confirmation or revert token is unknown, used or expired.
+	ErrInvalidToken = apperror.New(apperror.Validation, "invalid or expired link")
+)
+
+// EmailChangeConfig holds the link targets and lifetimes of the email change flow.
+type EmailChangeConfig struct {
+	ConfirmURL string // receives ?token=... and should POST it to the confirm endpoint
+	RevertURL  string // receives ?token=... and should POST it to the revert endpoint
+	ConfirmTTL time.Duration
+	RevertTTL  time.Duration
+}
+
+// EmailChangeService defines the interface for changing a user's email address.
+type EmailChangeService interface {
+	RequestEmailChange(ctx context.Context, userID int64, newEmail, currentPassword string) error
+	ConfirmEmailChange(ctx context.Context, token string) (sqlc.User, error)
+	RevertEmailChange(ctx context.Context, token string) error
+}
+
+type emailChangeServiceImpl struct {
+	userRepo        repository.UserRepository
+	emailChangeRepo repository.EmailChangeRepository
+	mailer          mailer.Mailer
+	auditLogger     AuditLogger
+	cfg             EmailChangeConfig
+}
+
+// NewEmailChangeService creates a new instance of EmailChangeService.
+func NewEmailChangeService(userRepo repository.UserRepository, emailChangeRepo repository.EmailChangeRepository, m mailer.Mailer, auditLogger AuditLogger, cfg EmailChangeConfig) EmailChangeService {
+	return &emailChangeServiceImpl{
+		userRepo:        userRepo,
+		emailChangeRepo: emailChangeRepo,
+		mailer:          m,
+		auditLogger:     auditLogger,
+		cfg:             cfg,
+	}
+}
+
+// RequestEmailChange records a pending change after re-checking the user's password.
+// The new address receives a confirmation link; the old address receives a revert link.
+// Nothing on the user row changes until the confirmation link is used.
+func (s *emailChangeServiceImpl) RequestEmailChange(ctx context.Context, userID int64, newEmail, currentPassword string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := util.CheckPasswordHash(currentPassword, user.HashedPassword)
+	if err != nil || !ok {
+		return ErrInvalidCredentials
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+	// Early check for a friendlier error; the unique constraint stays authoritative at confirm time
+	if _, err := s.userRepo.GetUserByEmail(ctx, newEmail); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, apperror.NotFound) {
+		return err
+	}
+
+	confirmToken, confirmHash, err := util.GenerateToken()
+	if err != nil {
+		return err
+	}
+	revertToken, revertHash, err := util.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	// A newer request supersedes the previous one
+	if err := s.emailChangeRepo.CancelPendingEmailChanges(ctx, userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	req, err := s.emailChangeRepo.CreateEmailChangeRequest(ctx, sqlc.CreateEmailChangeRequestParams{
+		UserID:           userID,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: confirmHash,
+		RevertTokenHash:  revertHash,
+		ExpiresAt:        now.Add(s.cfg.ConfirmTTL),
+		RevertExpiresAt:  now.Add(s.cfg.RevertTTL),
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err, "email_change_requests_one_pending_per_user") {
+			return ErrEmailChangeInProgress
+		}
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      req.NewEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this address for your account:\n\n%s\n\nThe link expires at %s. If you did not request this change, ignore this email.\n",
+			user.FirstName, linkWithToken(s.cfg.ConfirmURL, confirmToken), req.ExpiresAt.Format(time.RFC1123)),
+	}); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      req.OldEmail,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nA request was made to change the email address of your account to %s.\n\nIf this was not you, cancel or undo the change with this link:\n\n%s\n\nThe link stays valid until %s, even after the change has been confirmed.\n",
+			user.FirstName, req.NewEmail, linkWithToken(s.cfg.RevertURL, revertToken), req.RevertExpiresAt.Format(time.RFC1123)),
+	}); err != nil {
+		// The confirmation mail is already out, so keep the request; the old owner can still use their password
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to send email change notification", slog.Int64("user_id", userID), slog.Any("error", err))
+	}
+	return nil
+}
+
+// ConfirmEmailChange commits a pending change. The swap and the confirmation happen in a single
+// statement, so a concurrent signup or change to the same address is rejected by the unique constraint.
+func (s *emailChangeServiceImpl) ConfirmEmailChange(ctx context.Context, token string) (sqlc.User, error) {
+	user, err := s.emailChangeRepo.ConfirmEmailChange(ctx, util.HashToken(token))
+	if err != nil {
+		if errors.Is(err, apperror.NotFound) {
+			return sqlc.User{}, ErrInvalidToken
+		}
+		if repository.IsUniqueViolation(err, "users_email_key") {
+			return sqlc.User{}, ErrEmailTaken
+		}
+		return sqlc.User{}, err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{
+		Action:     AuditUserEmailConfirm,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]any{"email": audit.Change{New: user.Email}},
+	})
+	return user, nil
+}
+
+// RevertEmailChange cancels a pending change or restores the old address of a confirmed one.
+func (s *emailChangeServiceImpl) RevertEmailChange(ctx context.Context, token string) error {
+	reverted, err := s.emailChangeRepo.RevertEmailChange(ctx, util.HashToken(token))
+	if err != nil {
+		if errors.Is(err, apperror.NotFound) {
+			return ErrInvalidToken
+		}
+		if repository.IsUniqueViolation(err, "users_email_key") {
+			return ErrEmailTaken
+		}
+		return err
+	}
+	// The request may still have been pending, so only the address the user is left with is known
+	s.auditLogger.Record(ctx, AuditEvent{
+		Action:     AuditUserEmailRevert,
+		TargetType: "user",
+		TargetID:   reverted.UserID,
+		Details:    map[string]any{"email": audit.Change{New: reverted.OldEmail}},
+	})
+	return nil
+}
+
+func linkWithToken(baseURL, token string) string {
+	sep := "?"
+	if strings.Contains(baseURL, "?") {
+		sep = "&"
+	}
+	return baseURL + sep + "token=" + url.QueryEscape(token)
+}
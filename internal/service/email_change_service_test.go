@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/mailer"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const testPassword = "correct horse battery staple"
+
+// testPasswordHash uses a single PBKDF2 iteration; CheckPasswordHash reads the count from the hash,
+// and the real one would make every request take a second
+var testPasswordHash = sync.OnceValue(func() string {
+	salt := []byte("0123456789abcdef")
+	hash := pbkdf2.Key([]byte(testPassword), salt, 1, 32, sha256.New)
+	return fmt.Sprintf("pbkdf2-sha256:1:%s:%s", base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+})
+
+// emailChangeDB keeps users and email change requests in memory. Each method runs under one lock,
+// like the single statements of the queries, and follows their conditions and constraints.
+type emailChangeDB struct {
+	mu       sync.Mutex
+	users    map[int64]sqlc.User
+	requests []*sqlc.EmailChangeRequest
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23505", ConstraintName: constraint}
+}
+
+// emailTaken reports whether a user other than id has email, like the users_email_key index
+func (db *emailChangeDB) emailTaken(email string, id int64) bool {
+	for _, u := range db.users {
+		if u.ID != id && strings.EqualFold(u.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeUsers serves the lookups of the email change flow; other methods are not used
+type fakeUsers struct {
+	repository.UserRepository
+	db *emailChangeDB
+}
+
+func (r fakeUsers) GetUserByID(ctx context.Context, id int64) (sqlc.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	user, ok := r.db.users[id]
+	if !ok {
+		return sqlc.User{}, apperror.New(apperror.NotFound, "user not found")
+	}
+	return user, nil
+}
+
+func (r fakeUsers) GetUserByEmail(ctx context.Context, email string) (sqlc.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, u := range r.db.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return sqlc.User{}, apperror.New(apperror.NotFound, "user not found")
+}
+
+// fakeEmailChanges implements the queries of email_change_request.sql
+type fakeEmailChanges struct {
+	repository.EmailChangeRepository
+	db *emailChangeDB
+}
+
+func (r fakeEmailChanges) CancelPendingEmailChanges(ctx context.Context, userID int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, req := range r.db.requests {
+		if req.UserID == userID && !req.ConfirmedAt.Valid && !req.RevertedAt.Valid {
+			req.RevertedAt = timestamptzNow()
+		}
+	}
+	return nil
+}
+
+func (r fakeEmailChanges) CreateEmailChangeRequest(ctx context.Context, arg sqlc.CreateEmailChangeRequestParams) (sqlc.EmailChangeRequest, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, req := range r.db.requests {
+		if req.UserID == arg.UserID && !req.ConfirmedAt.Valid && !req.RevertedAt.Valid {
+			return sqlc.EmailChangeRequest{}, uniqueViolation("email_change_requests_one_pending_per_user")
+		}
+	}
+	req := &sqlc.EmailChangeRequest{
+		ID:               int64(len(r.db.requests) + 1),
+		UserID:           arg.UserID,
+		OldEmail:         arg.OldEmail,
+		NewEmail:         arg.NewEmail,
+		ConfirmTokenHash: arg.ConfirmTokenHash,
+		RevertTokenHash:  arg.RevertTokenHash,
+		ExpiresAt:        arg.ExpiresAt,
+		RevertExpiresAt:  arg.RevertExpiresAt,
+		CreatedAt:        time.Now(),
+	}
+	r.db.requests = append(r.db.requests, req)
+	return *req, nil
+}
+
+func (r fakeEmailChanges) ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (sqlc.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, req := range r.db.requests {
+		user := r.db.users[req.UserID]
+		if req.ConfirmTokenHash != confirmTokenHash || req.ConfirmedAt.Valid || req.RevertedAt.Valid ||
+			!req.ExpiresAt.After(time.Now()) || user.Email != req.OldEmail {
+			continue
+		}
+		if r.db.emailTaken(req.NewEmail, user.ID) {
+			return sqlc.User{}, uniqueViolation("users_email_key")
+		}
+		req.ConfirmedAt = timestamptzNow()
+		user.Email = req.NewEmail
+		r.db.users[user.ID] = user
+		return user, nil
+	}
+	return sqlc.User{}, apperror.New(apperror.NotFound, "email change request not found")
+}
+
+func (r fakeEmailChanges) RevertEmailChange(ctx context.Context, revertTokenHash string) (sqlc.RevertEmailChangeRow, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	for _, req := range r.db.requests {
+		if req.RevertTokenHash != revertTokenHash || req.RevertedAt.Valid || !req.RevertExpiresAt.After(time.Now()) {
+			continue
+		}
+		if user := r.db.users[req.UserID]; req.ConfirmedAt.Valid && user.Email == req.NewEmail {
+			if r.db.emailTaken(req.OldEmail, user.ID) {
+				return sqlc.RevertEmailChangeRow{}, uniqueViolation("users_email_key")
+			}
+			user.Email = req.OldEmail
+			r.db.users[user.ID] = user
+		}
+		req.RevertedAt = timestamptzNow()
+		return sqlc.RevertEmailChangeRow{UserID: req.UserID, OldEmail: req.OldEmail}, nil
+	}
+	return sqlc.RevertEmailChangeRow{}, apperror.New(apperror.NotFound, "email change request not found")
+}
+
+func timestamptzNow() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now(), Valid: true}
+}
+
+// recordingMailer keeps the sent messages
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// token returns the token of the last link sent to the address
+func (m *recordingMailer) token(t *testing.T, to string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To != to {
+			continue
+		}
+		for _, word := range strings.Fields(m.sent[i].Body) {
+			if link, err := url.Parse(word); err == nil && link.Query().Get("token") != "" {
+				return link.Query().Get("token")
+			}
+		}
+	}
+	t.Fatalf("no link was sent to %s", to)
+	return ""
+}
+
+// auditRecorder collects the recorded events
+type auditRecorder struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func (r *auditRecorder) Record(ctx context.Context, event AuditEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+const (
+	aliceID       = 1
+	aliceOldEmail = "alice@example.com"
+	aliceNewEmail = "alice@example.org"
+	bobID         = 2
+	bobsEmail     = "bob@example.com"
+)
+
+// emailChangeEnv is a service over an in-memory database holding alice and bob
+type emailChangeEnv struct {
+	service EmailChangeService
+	db      *emailChangeDB
+	mailer  *recordingMailer
+	audit   *auditRecorder
+}
+
+func newEmailChangeEnv(t *testing.T) *emailChangeEnv {
+	t.Helper()
+	db := &emailChangeDB{users: map[int64]sqlc.User{
+		aliceID: {ID: aliceID, FirstName: "Alice", Email: aliceOldEmail, HashedPassword: testPasswordHash()},
+		bobID:   {ID: bobID, FirstName: "Bob", Email: bobsEmail, HashedPassword: testPasswordHash()},
+	}}
+	e := &emailChangeEnv{db: db, mailer: &recordingMailer{}, audit: &auditRecorder{}}
+	e.service = NewEmailChangeService(fakeUsers{db: db}, fakeEmailChanges{db: db}, e.mailer, e.audit, EmailChangeConfig{
+		ConfirmURL: "https://app.example.com/email/confirm",
+		RevertURL:  "https://app.example.com/email/revert?source=mail",
+		ConfirmTTL: time.Hour,
+		RevertTTL:  24 * time.Hour,
+	})
+	return e
+}
+
+// request asks to change alice's address to aliceNewEmail and returns the tokens of the two links
+func (e *emailChangeEnv) request(t *testing.T) (confirmToken, revertToken string) {
+	t.Helper()
+	if err := e.service.RequestEmailChange(context.Background(), aliceID, aliceNewEmail, testPassword); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	return e.mailer.token(t, aliceNewEmail), e.mailer.token(t, aliceOldEmail)
+}
+
+func (e *emailChangeEnv) email(id int64) string {
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+	return e.db.users[id].Email
+}
+
+func (e *emailChangeEnv) setEmail(id int64, email string) {
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+	user := e.db.users[id]
+	user.Email = email
+	e.db.users[id] = user
+}
+
+func TestRequestEmailChange_Rejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "wrong password", email: aliceNewEmail, password: "guess", wantErr: ErrInvalidCredentials},
+		{name: "same address", email: strings.ToUpper(aliceOldEmail), password: testPassword, wantErr: ErrEmailUnchanged},
+		{name: "address of another user", email: bobsEmail, password: testPassword, wantErr: ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEmailChangeEnv(t)
+			err := e.service.RequestEmailChange(context.Background(), aliceID, tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequestEmailChange = %v, want %v", err, tt.wantErr)
+			}
+			if len(e.mailer.sent) != 0 || len(e.db.requests) != 0 {
+				t.Errorf("a rejected request sent %d mails and stored %d requests", len(e.mailer.sent), len(e.db.requests))
+			}
+		})
+	}
+}
+
+func TestEmailChange_Sequences(t *testing.T) {
+	type step struct {
+		name    string
+		do      func(e *emailChangeEnv, confirm, revert string) error
+		wantErr error
+	}
+	confirm := func(e *emailChangeEnv, token, _ string) error {
+		_, err := e.service.ConfirmEmailChange(context.Background(), token)
+		return err
+	}
+	revert := func(e *emailChangeEnv, _, token string) error {
+		return e.service.RevertEmailChange(context.Background(), token)
+	}
+	tests := []struct {
+		name      string
+		steps     []step
+		wantEmail string
+	}{
+		{
+			name:      "confirm",
+			steps:     []step{{name: "confirm", do: confirm}},
+			wantEmail: aliceNewEmail,
+		},
+		{
+			name:      "confirm then revert",
+			steps:     []step{{name: "confirm", do: confirm}, {name: "revert", do: revert}},
+			wantEmail: aliceOldEmail,
+		},
+		{
+			name:      "revert then confirm",
+			steps:     []step{{name: "revert", do: revert}, {name: "confirm", do: confirm, wantErr: ErrInvalidToken}},
+			wantEmail: aliceOldEmail,
+		},
+		{
+			name:      "confirm twice",
+			steps:     []step{{name: "confirm", do: confirm}, {name: "confirm again", do: confirm, wantErr: ErrInvalidToken}},
+			wantEmail: aliceNewEmail,
+		},
+		{
+			name:      "revert twice",
+			steps:     []step{{name: "confirm", do: confirm}, {name: "revert", do: revert}, {name: "revert again", do: revert, wantErr: ErrInvalidToken}},
+			wantEmail: aliceOldEmail,
+		},
+		{
+			name: "address taken before the confirmation",
+			steps: []step{
+				{name: "bob takes the address", do: func(e *emailChangeEnv, _, _ string) error { e.setEmail(bobID, aliceNewEmail); return nil }},
+				{name: "confirm", do: confirm, wantErr: ErrEmailTaken},
+			},
+			wantEmail: aliceOldEmail,
+		},
+		{
+			name: "old address taken before the revert",
+			steps: []step{
+				{name: "confirm", do: confirm},
+				{name: "bob takes the old address", do: func(e *emailChangeEnv, _, _ string) error { e.setEmail(bobID, aliceOldEmail); return nil }},
+				{name: "revert", do: revert, wantErr: ErrEmailTaken},
+			},
+			wantEmail: aliceNewEmail,
+		},
+		{
+			name: "address changed otherwise before the confirmation",
+			steps: []step{
+				{name: "admin changes the address", do: func(e *emailChangeEnv, _, _ string) error { e.setEmail(aliceID, "alice@example.net"); return nil }},
+				{name: "confirm", do: confirm, wantErr: ErrInvalidToken},
+			},
+			wantEmail: "alice@example.net",
+		},
+		{
+			name: "superseded by a newer request",
+			steps: []step{
+				{name: "request again", do: func(e *emailChangeEnv, _, _ string) error {
+					return e.service.RequestEmailChange(context.Background(), aliceID, aliceNewEmail, testPassword)
+				}},
+				{name: "confirm the first request", do: confirm, wantErr: ErrInvalidToken},
+			},
+			wantEmail: aliceOldEmail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEmailChangeEnv(t)
+			confirmToken, revertToken := e.request(t)
+			for _, s := range tt.steps {
+				if err := s.do(e, confirmToken, revertToken); !errors.Is(err, s.wantErr) {
+					t.Fatalf("%s = %v, want %v", s.name, err, s.wantErr)
+				}
+			}
+			if got := e.email(aliceID); got != tt.wantEmail {
+				t.Errorf("email = %q, want %q", got, tt.wantEmail)
+			}
+		})
+	}
+}
+
+// TestEmailChange_ConfirmRacesRevert runs the two links at once: whichever wins, the revert
+// must leave the old address in place, as the old owner asked for
+func TestEmailChange_ConfirmRacesRevert(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		e := newEmailChangeEnv(t)
+		confirmToken, revertToken := e.request(t)
+
+		var confirmErr, revertErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, confirmErr = e.service.ConfirmEmailChange(context.Background(), confirmToken)
+		}()
+		go func() {
+			defer wg.Done()
+			revertErr = e.service.RevertEmailChange(context.Background(), revertToken)
+		}()
+		wg.Wait()
+
+		if revertErr != nil {
+			t.Fatalf("run %d: revert = %v, want success whether or not the confirmation came first", i, revertErr)
+		}
+		if confirmErr != nil && !errors.Is(confirmErr, ErrInvalidToken) {
+			t.Fatalf("run %d: confirm = %v, want success or ErrInvalidToken", i, confirmErr)
+		}
+		if got := e.email(aliceID); got != aliceOldEmail {
+			t.Fatalf("run %d: email = %q after confirm (%v) and revert, want %q", i, got, confirmErr, aliceOldEmail)
+		}
+	}
+}
+
+// TestRequestEmailChange_Concurrent sends two requests at once: the loser of the race on the
+// pending request gets ErrEmailChangeInProgress, and a single request stays pending
+func TestRequestEmailChange_Concurrent(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		e := newEmailChangeEnv(t)
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for j := range errs {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				errs[j] = e.service.RequestEmailChange(context.Background(), aliceID, aliceNewEmail, testPassword)
+			}(j)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, ErrEmailChangeInProgress) {
+				t.Fatalf("run %d: RequestEmailChange = %v, want success or ErrEmailChangeInProgress", i, err)
+			}
+		}
+		pending := 0
+		for _, req := range e.db.requests {
+			if !req.ConfirmedAt.Valid && !req.RevertedAt.Valid {
+				pending++
+			}
+		}
+		if pending != 1 {
+			t.Fatalf("run %d: %d pending requests, want 1", i, pending)
+		}
+	}
+}
+
+func TestEmailChange_RecordsAuditEvents(t *testing.T) {
+	e := newEmailChangeEnv(t)
+	confirmToken, revertToken := e.request(t)
+	if _, err := e.service.ConfirmEmailChange(context.Background(), confirmToken); err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if err := e.service.RevertEmailChange(context.Background(), revertToken); err != nil {
+		t.Fatalf("RevertEmailChange: %v", err)
+	}
+
+	want := []string{AuditUserEmailConfirm, AuditUserEmailRevert}
+	if len(e.audit.events) != len(want) {
+		t.Fatalf("recorded %d events, want %d", len(e.audit.events), len(want))
+	}
+	for i, event := range e.audit.events {
+		if event.Action != want[i] || event.TargetID != aliceID {
+			t.Errorf("event %d = %s on %d, want %s on %d", i, event.Action, event.TargetID, want[i], aliceID)
+		}
+	}
+	// The revert link keeps the query of RevertURL
+	if !strings.Contains(e.mailer.sent[1].Body, "?source=mail&token=") {
+		t.Errorf("revert mail %q does not append the token to the query of RevertURL", e.mailer.sent[1].Body)
+	}
+}
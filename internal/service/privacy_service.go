@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/logging"
+	"github.com/yourusername/yourprojectname/internal/privacy"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/storage"
+	"github.com/yourusername/yourprojectname/internal/util"
+)
+
+// ErrExportQueueFull indicates that too many exports are waiting to be built.
+var ErrExportQueueFull = apperror.New(apperror.Unavailable, "too many exports in progress, try again later")
+
+// PrivacyConfig holds the settings of data exports.
+type PrivacyConfig struct {
+	Workers   int           // number of exports built concurrently
+	ExportTTL time.Duration // how long finished archives are kept
+	URLTTL    time.Duration // lifetime of signed download URLs
+}
+
+// PrivacyService defines the interface for GDPR data exports and erasure.
+type PrivacyService interface {
+	RequestExport(ctx context.Context, userID int64) (sqlc.DataExport, error)
+	GetExport(ctx context.Context, userID, exportID int64) (sqlc.DataExport, string, error)
+	EraseUser(ctx context.Context, userID int64, currentPassword string) error
+	// Run builds queued exports and purges expired archives until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type privacyServiceImpl struct {
+	userRepo       repository.UserRepository
+	dataExportRepo repository.DataExportRepository
+	registry       *privacy.Registry
+	blobStore      storage.BlobStore
+	auditLogger    AuditLogger
+	cfg            PrivacyConfig
+	queue          chan int64
+	startedAt      time.Time
+}
+
+// NewPrivacyService creates a new instance of PrivacyService.
+// Every component storing personal data must be registered with registry.
+func NewPrivacyService(userRepo repository.UserRepository, dataExportRepo repository.DataExportRepository, registry *privacy.Registry, blobStore storage.BlobStore, auditLogger AuditLogger, cfg PrivacyConfig) PrivacyService {
+	return &privacyServiceImpl{
+		userRepo:       userRepo,
+		dataExportRepo: dataExportRepo,
+		registry:       registry,
+		blobStore:      blobStore,
+		auditLogger:    auditLogger,
+		cfg:            cfg,
+		queue:          make(chan int64, 100),
+		startedAt:      time.Now(),
+	}
+}
+
+// RequestExport queues an export; the archive is built in the background.
+func (s *privacyServiceImpl) RequestExport(ctx context.Context, userID int64) (sqlc.DataExport, error) {
+	export, err := s.dataExportRepo.CreateDataExport(ctx, userID)
+	if err != nil {
+		return sqlc.DataExport{}, err
+	}
+
+	select {
+	case s.queue <- export.ID:
+		return export, nil
+	default:
+		s.failExport(export.ID, ErrExportQueueFull)
+		return sqlc.DataExport{}, ErrExportQueueFull
+	}
+}
+
+// GetExport returns an export of the user and, once completed, a signed download URL.
+func (s *privacyServiceImpl) GetExport(ctx context.Context, userID, exportID int64) (sqlc.DataExport, string, error) {
+	export, err := s.dataExportRepo.GetDataExport(ctx, sqlc.GetDataExportParams{ID: exportID, UserID: userID})
+	if err != nil {
+		return sqlc.DataExport{}, "", err
+	}
+	if export.Status != "completed" || !export.BlobKey.Valid {
+		return export, "", nil
+	}
+	if export.ExpiresAt.Valid && export.ExpiresAt.Time.Before(time.Now()) {
+		return export, "", nil
+	}
+
+	url, err := s.blobStore.SignedURL(ctx, export.BlobKey.String, s.cfg.URLTTL)
+	if err != nil {
+		return sqlc.DataExport{}, "", err
+	}
+	return export, url, nil
+}
+
+// EraseUser removes the user's export archives and runs every registered erase hook.
+// The password is re-checked because erasure cannot be undone.
+func (s *privacyServiceImpl) EraseUser(ctx context.Context, userID int64, currentPassword string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := util.CheckPasswordHash(currentPassword, user.HashedPassword)
+	if err != nil || !ok {
+		return ErrInvalidCredentials
+	}
+
+	exports, err := s.dataExportRepo.ListDataExportsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		if export.BlobKey.Valid {
+			if err := s.blobStore.Delete(ctx, export.BlobKey.String); err != nil {
+				return fmt.Errorf("failed to delete export archive: %w", err)
+			}
+		}
+	}
+
+	if err := s.registry.Erase(ctx, userID); err != nil {
+		return err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserErase, TargetType: "user", TargetID: userID})
+	return nil
+}
+
+// Run starts the export workers and the purge loop and blocks until ctx is cancelled.
+func (s *privacyServiceImpl) Run(ctx context.Context) {
+	// Exports queued by a previous process were lost with its in-memory queue
+	if err := s.dataExportRepo.FailStaleDataExports(ctx, s.startedAt); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to mark stale data exports", slog.Any("error", err))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(1, s.cfg.Workers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-s.queue:
+					s.buildExport(ctx, id)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			s.purgeExpired(ctx)
+		}
+	}
+}
+
+// buildExport writes the archive to a temporary file so its size is known before uploading
+func (s *privacyServiceImpl) buildExport(ctx context.Context, exportID int64) {
+	export, err := s.dataExportRepo.StartDataExport(ctx, exportID)
+	if err != nil {
+		if !errors.Is(err, apperror.NotFound) {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to start data export", slog.Int64("export_id", exportID), slog.Any("error", err))
+		}
+		return
+	}
+
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		s.failExport(exportID, err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := privacy.NewZipExportWriter(tmp)
+	err = zw.WriteJSON("manifest.json", map[string]any{
+		"user_id":      export.UserID,
+		"export_id":    export.ID,
+		"generated_at": time.Now().UTC(),
+	})
+	if err == nil {
+		err = s.registry.Export(ctx, export.UserID, zw)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		s.failExport(exportID, err)
+		return
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		s.failExport(exportID, err)
+		return
+	}
+
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		s.failExport(exportID, err)
+		return
+	}
+	key := fmt.Sprintf("exports/%d/%d-%s.zip", export.UserID, export.ID, hex.EncodeToString(nonce))
+	if err := s.blobStore.Put(ctx, key, tmp, size, "application/zip"); err != nil {
+		s.failExport(exportID, err)
+		return
+	}
+
+	err = s.dataExportRepo.CompleteDataExport(ctx, sqlc.CompleteDataExportParams{
+		ID:        exportID,
+		BlobKey:   pgtype.Text{String: key, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.cfg.ExportTTL), Valid: true},
+	})
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to complete data export", slog.Int64("export_id", exportID), slog.Any("error", err))
+		_ = s.blobStore.Delete(context.Background(), key)
+	}
+}
+
+// exportFailedMessage is stored for failed exports and shown to clients; the cause is only logged,
+// as database and file system errors may reveal internals
+const exportFailedMessage = "the export could not be created, please request a new one"
+
+func (s *privacyServiceImpl) failExport(exportID int64, cause error) {
+	slog.Error("Data export failed", slog.Int64("export_id", exportID), slog.Any("error", cause))
+	// The request context may already be gone; the failure must still be recorded
+	err := s.dataExportRepo.FailDataExport(context.Background(), sqlc.FailDataExportParams{
+		ID:    exportID,
+		Error: pgtype.Text{String: exportFailedMessage, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to mark data export as failed", slog.Int64("export_id", exportID), slog.Any("error", err))
+	}
+}
+
+func (s *privacyServiceImpl) purgeExpired(ctx context.Context) {
+	expired, err := s.dataExportRepo.ListExpiredDataExports(ctx)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to list expired data exports", slog.Any("error", err))
+		return
+	}
+	for _, export := range expired {
+		if export.BlobKey.Valid {
+			if err := s.blobStore.Delete(ctx, export.BlobKey.String); err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to delete expired export archive", slog.Int64("export_id", export.ID), slog.Any("error", err))
+				continue
+			}
+		}
+		if err := s.dataExportRepo.DeleteDataExport(ctx, export.ID); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to delete expired data export", slog.Int64("export_id", export.ID), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// ErrUnsupportedExportFormat indicates that the export format is not csv, ndjson or parquet.
+var ErrUnsupportedExportFormat = apperror.New(apperror.Validation, "unsupported export format, expected csv, ndjson or parquet")
+
+// exportBatchSize is the number of rows fetched from the cursor and written per flush
+const exportBatchSize = 5000
+
+// exportContentTypes maps the supported formats to their media types
+var exportContentTypes = map[string]string{
+	"csv":     "text/csv; charset=utf-8",
+	"ndjson":  "application/x-ndjson",
+	"parquet": "application/vnd.apache.parquet",
+}
+
+// UserExportService defines the interface for streaming user dumps.
+type UserExportService interface {
+	// ContentType returns the media type of format, or ErrUnsupportedExportFormat.
+	ContentType(format string) (string, error)
+	// ExportUsers streams all users matching filter to w; limit and offset of filter are ignored.
+	ExportUsers(ctx context.Context, w io.Writer, format string, filter sqlc.ListUsersParams) error
+}
+
+type userExportServiceImpl struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserExportService creates a new instance of UserExportService.
+func NewUserExportService(userRepo repository.UserRepository) UserExportService {
+	return &userExportServiceImpl{
+		userRepo: userRepo,
+	}
+}
+
+// ContentType returns the media type of an export format.
+func (s *userExportServiceImpl) ContentType(format string) (string, error) {
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return "", ErrUnsupportedExportFormat
+	}
+	return contentType, nil
+}
+
+// ExportUsers writes users batch by batch and flushes w after each batch if it supports flushing,
+// so neither the server nor the client holds the whole table.
+func (s *userExportServiceImpl) ExportUsers(ctx context.Context, w io.Writer, format string, filter sqlc.ListUsersParams) error {
+	var enc userExportEncoder
+	switch format {
+	case "csv":
+		enc = newCSVUserExportEncoder(w)
+	case "ndjson":
+		enc = &ndjsonUserExportEncoder{enc: json.NewEncoder(w)}
+	case "parquet":
+		// Without its own write buffer the writer hands each row group to w when the batch is flushed
+		enc = &parquetUserExportEncoder{w: parquet.NewGenericWriter[repository.UserExportRow](w,
+			parquet.Compression(&parquet.Snappy),
+			parquet.WriteBufferSize(0),
+		)}
+	default:
+		return ErrUnsupportedExportFormat
+	}
+
+	flusher, _ := w.(interface{ Flush() })
+	err := s.userRepo.StreamUsers(ctx, filter, exportBatchSize, func(rows []repository.UserExportRow) error {
+		if err := enc.write(rows); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return enc.close()
+}
+
+// userExportEncoder writes one export format
+type userExportEncoder interface {
+	write(rows []repository.UserExportRow) error
+	close() error
+}
+
+type csvUserExportEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVUserExportEncoder(w io.Writer) *csvUserExportEncoder {
+	cw := csv.NewWriter(w)
+	// The header is written even for an empty result; errors surface on the first flush
+	_ = cw.Write([]string{"id", "first_name", "last_name", "email", "is_admin", "created_at", "updated_at"})
+	return &csvUserExportEncoder{w: cw}
+}
+
+func (e *csvUserExportEncoder) write(rows []repository.UserExportRow) error {
+	for _, u := range rows {
+		err := e.w.Write([]string{
+			strconv.FormatInt(u.ID, 10),
+			u.FirstName,
+			u.LastName,
+			u.Email,
+			strconv.FormatBool(u.IsAdmin),
+			u.CreatedAt.Format(time.RFC3339),
+			u.UpdatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvUserExportEncoder) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonUserExportEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonUserExportEncoder) write(rows []repository.UserExportRow) error {
+	for _, u := range rows {
+		if err := e.enc.Encode(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ndjsonUserExportEncoder) close() error {
+	return nil
+}
+
+// parquetUserExportEncoder writes one row group per batch; the footer is written on close
+type parquetUserExportEncoder struct {
+	w *parquet.GenericWriter[repository.UserExportRow]
+}
+
+func (e *parquetUserExportEncoder) write(rows []repository.UserExportRow) error {
+	if _, err := e.w.Write(rows); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *parquetUserExportEncoder) close() error {
+	return e.w.Close()
+}
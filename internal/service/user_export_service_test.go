@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// fakeExportUsers streams its batches like the cursor of StreamUsers
+type fakeExportUsers struct {
+	repository.UserRepository
+	batches   [][]repository.UserExportRow
+	batchSize int // as requested by the service
+}
+
+func (r *fakeExportUsers) StreamUsers(ctx context.Context, filter sqlc.ListUsersParams, batchSize int, fn func([]repository.UserExportRow) error) error {
+	r.batchSize = batchSize
+	for _, batch := range r.batches {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushingBuffer records how many bytes were written at each flush
+type flushingBuffer struct {
+	bytes.Buffer
+	flushedAt []int
+}
+
+func (b *flushingBuffer) Flush() {
+	b.flushedAt = append(b.flushedAt, b.Len())
+}
+
+var (
+	exportAlice = repository.UserExportRow{
+		ID: 2, FirstName: "Alice", LastName: "Smith, Jr.", Email: "alice@example.com", IsAdmin: true,
+		CreatedAt: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC),
+	}
+	exportBob = repository.UserExportRow{
+		ID: 1, FirstName: "Bob", LastName: "Jones", Email: "bob@example.com",
+		CreatedAt: time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+)
+
+// decodeExport parses an export back into rows, with times in UTC
+func decodeExport(t *testing.T, format string, data []byte) []repository.UserExportRow {
+	t.Helper()
+	var rows []repository.UserExportRow
+	switch format {
+	case "csv":
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			t.Fatalf("reading csv: %v", err)
+		}
+		if want := []string{"id", "first_name", "last_name", "email", "is_admin", "created_at", "updated_at"}; !reflect.DeepEqual(records[0], want) {
+			t.Fatalf("csv header = %v, want %v", records[0], want)
+		}
+		for _, r := range records[1:] {
+			// Every column is a string in csv, so the row is rebuilt through JSON
+			line := `{"id":` + r[0] + `,"first_name":"` + r[1] + `","last_name":"` + r[2] + `","email":"` + r[3] +
+				`","is_admin":` + r[4] + `,"created_at":"` + r[5] + `","updated_at":"` + r[6] + `"}`
+			var u repository.UserExportRow
+			if err := json.Unmarshal([]byte(line), &u); err != nil {
+				t.Fatalf("csv row %v: %v", r, err)
+			}
+			rows = append(rows, u)
+		}
+	case "ndjson":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		for dec.More() {
+			var u repository.UserExportRow
+			if err := dec.Decode(&u); err != nil {
+				t.Fatalf("reading ndjson: %v", err)
+			}
+			rows = append(rows, u)
+		}
+	case "parquet":
+		var err error
+		rows, err = parquet.Read[repository.UserExportRow](bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("reading parquet: %v", err)
+		}
+	}
+	for i := range rows {
+		rows[i].CreatedAt = rows[i].CreatedAt.UTC()
+		rows[i].UpdatedAt = rows[i].UpdatedAt.UTC()
+	}
+	return rows
+}
+
+func TestExportUsers_Formats(t *testing.T) {
+	tests := []struct {
+		format          string
+		wantContentType string
+	}{
+		{format: "csv", wantContentType: "text/csv; charset=utf-8"},
+		{format: "ndjson", wantContentType: "application/x-ndjson"},
+		{format: "parquet", wantContentType: "application/vnd.apache.parquet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			repo := &fakeExportUsers{batches: [][]repository.UserExportRow{{exportAlice}, {exportBob}}}
+			svc := NewUserExportService(repo)
+
+			contentType, err := svc.ContentType(tt.format)
+			if err != nil || contentType != tt.wantContentType {
+				t.Errorf("ContentType = %q, %v; want %q", contentType, err, tt.wantContentType)
+			}
+
+			var out flushingBuffer
+			if err := svc.ExportUsers(context.Background(), &out, tt.format, sqlc.ListUsersParams{}); err != nil {
+				t.Fatalf("ExportUsers: %v", err)
+			}
+			if got, want := decodeExport(t, tt.format, out.Bytes()), []repository.UserExportRow{exportAlice, exportBob}; !reflect.DeepEqual(got, want) {
+				t.Errorf("exported %+v\nwant %+v", got, want)
+			}
+			if repo.batchSize != exportBatchSize {
+				t.Errorf("fetched batches of %d, want %d", repo.batchSize, exportBatchSize)
+			}
+			// Each batch reaches the writer before it is flushed, so clients receive rows as they are read
+			if len(out.flushedAt) != 2 || out.flushedAt[0] == 0 || out.flushedAt[1] <= out.flushedAt[0] {
+				t.Errorf("flushed at %v bytes, want one flush after each written batch", out.flushedAt)
+			}
+		})
+	}
+}
+
+func TestExportUsers_Empty(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string // a prefix of the output
+	}{
+		{format: "csv", want: "id,first_name,last_name,email,is_admin,created_at,updated_at\n"},
+		{format: "ndjson", want: ""},
+		{format: "parquet", want: "PAR1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := NewUserExportService(&fakeExportUsers{}).ExportUsers(context.Background(), &out, tt.format, sqlc.ListUsersParams{}); err != nil {
+				t.Fatalf("ExportUsers: %v", err)
+			}
+			if !strings.HasPrefix(out.String(), tt.want) || (tt.want == "" && out.Len() != 0) {
+				t.Errorf("output = %q, want it to start with %q", out.String(), tt.want)
+			}
+			if rows := decodeExport(t, tt.format, out.Bytes()); len(rows) != 0 {
+				t.Errorf("exported %+v, want no rows", rows)
+			}
+		})
+	}
+}
+
+func TestExportUsers_UnsupportedFormat(t *testing.T) {
+	repo := &fakeExportUsers{batches: [][]repository.UserExportRow{{exportAlice}}}
+	svc := NewUserExportService(repo)
+
+	if _, err := svc.ContentType("xlsx"); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("ContentType = %v, want ErrUnsupportedExportFormat", err)
+	}
+	var out bytes.Buffer
+	if err := svc.ExportUsers(context.Background(), &out, "xlsx", sqlc.ListUsersParams{}); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("ExportUsers = %v, want ErrUnsupportedExportFormat", err)
+	}
+	if out.Len() != 0 || repo.batchSize != 0 {
+		t.Errorf("an unsupported format wrote %q and streamed batches of %d", out.String(), repo.batchSize)
+	}
+}
+
+// failingWriter fails every write, like a client that went away
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errClientGone }
+
+var errClientGone = errors.New("client went away")
+
+func TestExportUsers_StopsOnWriteError(t *testing.T) {
+	for _, format := range []string{"csv", "ndjson", "parquet"} {
+		t.Run(format, func(t *testing.T) {
+			repo := &fakeExportUsers{batches: [][]repository.UserExportRow{{exportAlice}, {exportBob}}}
+			err := NewUserExportService(repo).ExportUsers(context.Background(), failingWriter{}, format, sqlc.ListUsersParams{})
+			if !errors.Is(err, errClientGone) {
+				t.Errorf("ExportUsers = %v, want the error of the writer", err)
+			}
+		})
+	}
+}
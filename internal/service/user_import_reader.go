@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+// maxNDJSONLine bounds a single NDJSON record
+const maxNDJSONLine = 1 << 20
+
+// importReader yields import rows one at a time.
+// A returned *importRowError affects only that row; any other error aborts the import.
+type importReader interface {
+	next() (row int, data ImportUserRow, err error)
+}
+
+// importRowError is a parse error confined to a single row
+type importRowError struct {
+	msg string
+}
+
+func (e *importRowError) Error() string {
+	return e.msg
+}
+
+func newImportReader(format string, r io.Reader) (importReader, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return newCSVImportReader(r)
+	case "ndjson", "jsonl":
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), maxNDJSONLine)
+		return &ndjsonImportReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+// csvImportReader reads CSV with a header row; columns may appear in any order
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // column count mismatches are reported per row
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, apperror.Wrapf(apperror.Validation, ErrInvalidImport, "invalid import file: empty file, expected a csv header row")
+		}
+		return nil, apperror.Wrapf(apperror.Validation, ErrInvalidImport, "invalid import file: unreadable csv header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"first_name", "last_name", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, apperror.Wrapf(apperror.Validation, ErrInvalidImport, "invalid import file: csv header is missing the %q column", required)
+		}
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) next() (int, ImportUserRow, error) {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, ImportUserRow{}, io.EOF
+	}
+	c.row++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return c.row, ImportUserRow{}, &importRowError{msg: "malformed csv: " + parseErr.Err.Error()}
+	}
+	if err != nil {
+		return c.row, ImportUserRow{}, err
+	}
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	data := ImportUserRow{
+		FirstName: field("first_name"),
+		LastName:  field("last_name"),
+		Email:     field("email"),
+		Password:  field("password"),
+	}
+	if v := field("is_admin"); v != "" {
+		isAdmin, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.row, data, &importRowError{msg: "is_admin must be true or false"}
+		}
+		data.IsAdmin = isAdmin
+	}
+	return c.row, data, nil
+}
+
+// ndjsonImportReader reads one JSON object per line; blank lines are skipped
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (n *ndjsonImportReader) next() (int, ImportUserRow, error) {
+	for n.scanner.Scan() {
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n.row++
+
+		var data ImportUserRow
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&data); err != nil {
+			return n.row, ImportUserRow{}, &importRowError{msg: "malformed json: " + err.Error()}
+		}
+		data.FirstName = strings.TrimSpace(data.FirstName)
+		data.LastName = strings.TrimSpace(data.LastName)
+		data.Email = strings.TrimSpace(data.Email)
+		return n.row, data, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return 0, ImportUserRow{}, apperror.Wrapf(apperror.Validation, ErrInvalidImport, "invalid import file: ndjson line %d exceeds %d bytes", n.row+1, maxNDJSONLine)
+		}
+		return 0, ImportUserRow{}, fmt.Errorf("failed to read ndjson: %w", err)
+	}
+	return 0, ImportUserRow{}, io.EOF
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/repository"
+	"github.com/yourusername/yourprojectname/internal/util"
+)
+
+var (
+	// ErrUnsupportedImportFormat indicates that the import format is neither CSV nor NDJSON.
+	ErrUnsupportedImportFormat = apperror.New(apperror.Validation, "unsupported import format, expected csv or ndjson")
+	// ErrInvalidImport indicates that the import file as a whole cannot be processed, e.g. a missing CSV column.
+	ErrInvalidImport = apperror.New(apperror.Validation, "invalid import file")
+)
+
+// ImportUserRow is one user record of an import file.
+// Field names match the CSV header and the NDJSON keys.
+type ImportUserRow struct {
+	FirstName string `json:"first_name" validate:"required,max=255"`
+	LastName  string `json:"last_name" validate:"required,max=255"`
+	Email     string `json:"email" validate:"required,email,max=255"`
+	Password  string `json:"password" validate:"required,min=8"`
+	IsAdmin   bool   `json:"is_admin"`
+}
+
+// ImportOptions controls a bulk import.
+type ImportOptions struct {
+	Format    string // "csv" or "ndjson"
+	DryRun    bool   // validate and check for conflicts without hashing or inserting
+	BatchSize int    // rows per COPY batch
+}
+
+// ImportRowError lists the problems of a rejected row. Row is 1-based and excludes the CSV header.
+type ImportRowError struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// ImportReport summarizes a bulk import. In a dry run, Imported counts the rows that would be inserted.
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// UserImportService defines the interface for bulk-loading users.
+type UserImportService interface {
+	ImportUsers(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error)
+}
+
+type userImportServiceImpl struct {
+	userRepo    repository.UserRepository
+	auditLogger AuditLogger
+	validate    *validator.Validate
+	hashWorkers int
+}
+
+// NewUserImportService creates a new instance of UserImportService.
+// hashWorkers bounds the parallel password hashing; 0 uses one worker per CPU.
+func NewUserImportService(userRepo repository.UserRepository, auditLogger AuditLogger, hashWorkers int) UserImportService {
+	if hashWorkers <= 0 {
+		hashWorkers = runtime.NumCPU()
+	}
+	return &userImportServiceImpl{
+		userRepo:    userRepo,
+		auditLogger: auditLogger,
+		validate:    validator.New(),
+		hashWorkers: hashWorkers,
+	}
+}
+
+// pendingRow is a row that passed validation and waits for insertion
+type pendingRow struct {
+	row  int
+	data ImportUserRow
+}
+
+// ImportUsers streams rows from r, validates them and inserts valid rows batch by batch with COPY.
+// Invalid rows are reported and skipped; only I/O and database failures abort the import.
+// One audit event summarizes each import that inserted rows.
+func (s *userImportServiceImpl) ImportUsers(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	report, err := s.importUsers(ctx, r, opts)
+	if !opts.DryRun && report.Imported > 0 {
+		s.auditLogger.Record(ctx, AuditEvent{
+			Action:     AuditUserImport,
+			TargetType: "user",
+			Details:    map[string]any{"total": report.Total, "imported": report.Imported, "failed": report.Failed},
+		})
+	}
+	return report, err
+}
+
+func (s *userImportServiceImpl) importUsers(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: opts.DryRun, Errors: []ImportRowError{}}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	reader, err := newImportReader(opts.Format, r)
+	if err != nil {
+		return report, err
+	}
+
+	// Duplicates inside the file are caught here, duplicates against the table by ListExistingEmails
+	seen := make(map[string]int)
+	batch := make([]pendingRow, 0, opts.BatchSize)
+
+	for eof := false; !eof; {
+		row, data, err := reader.next()
+		switch {
+		case errors.Is(err, io.EOF):
+			eof = true
+		case err != nil:
+			var rowErr *importRowError
+			if !errors.As(err, &rowErr) {
+				return report, err
+			}
+			report.Total++
+			report.addError(row, "", rowErr.Error())
+		default:
+			report.Total++
+			if problems := s.validateRow(data); len(problems) > 0 {
+				report.addError(row, data.Email, problems...)
+			} else if first, dup := seen[data.Email]; dup {
+				report.addError(row, data.Email, fmt.Sprintf("duplicate email, first used in row %d", first))
+			} else {
+				seen[data.Email] = row
+				batch = append(batch, pendingRow{row: row, data: data})
+			}
+		}
+
+		if len(batch) == opts.BatchSize || (eof && len(batch) > 0) {
+			if err := s.flush(ctx, batch, opts.DryRun, &report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	return report, nil
+}
+
+// flush drops rows whose email already exists, hashes the rest and inserts them with COPY
+func (s *userImportServiceImpl) flush(ctx context.Context, batch []pendingRow, dryRun bool, report *ImportReport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	emails := make([]string, len(batch))
+	for i, p := range batch {
+		emails[i] = p.data.Email
+	}
+	existing, err := s.userRepo.ListExistingEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		taken[email] = true
+	}
+
+	valid := make([]pendingRow, 0, len(batch))
+	for _, p := range batch {
+		if taken[p.data.Email] {
+			report.addError(p.row, p.data.Email, "email already exists")
+			continue
+		}
+		valid = append(valid, p)
+	}
+	if dryRun || len(valid) == 0 {
+		report.Imported += len(valid)
+		return nil
+	}
+
+	params, err := s.hashAll(ctx, valid)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.userRepo.CopyUsers(ctx, params); err == nil {
+		report.Imported += len(params)
+		return nil
+	} else if ctx.Err() != nil {
+		return err
+	}
+
+	// COPY is all-or-nothing; a concurrent insert can still fail the batch, so retry row by row to pinpoint it
+	for i, p := range params {
+		if _, err := s.userRepo.CopyUsers(ctx, []sqlc.CopyUsersParams{p}); err != nil {
+			if !repository.IsUniqueViolation(err, "") {
+				return err
+			}
+			report.addError(valid[i].row, p.Email, "email already exists")
+			continue
+		}
+		report.Imported++
+	}
+	return nil
+}
+
+// hashAll hashes passwords on a bounded worker pool; PBKDF2 is CPU-bound, so sequential hashing dominates imports
+func (s *userImportServiceImpl) hashAll(ctx context.Context, rows []pendingRow) ([]sqlc.CopyUsersParams, error) {
+	params := make([]sqlc.CopyUsersParams, len(rows))
+	jobs := make(chan int)
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.hashWorkers, len(rows)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				hashed, err := util.HashPassword(rows[i].data.Password)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					continue
+				}
+				params[i] = sqlc.CopyUsersParams{
+					FirstName:      rows[i].data.FirstName,
+					LastName:       rows[i].data.LastName,
+					Email:          rows[i].data.Email,
+					HashedPassword: hashed,
+					IsAdmin:        rows[i].data.IsAdmin,
+				}
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range rows {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-errs:
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	default:
+		return params, nil
+	}
+}
+
+// validateRow returns one message per failed rule, keyed by the JSON field name
+func (s *userImportServiceImpl) validateRow(row ImportUserRow) []string {
+	err := s.validate.Struct(row)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil
+	}
+	problems := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		field := importFieldNames[fe.Field()]
+		switch fe.Tag() {
+		case "required":
+			problems = append(problems, field+" is required")
+		case "email":
+			problems = append(problems, field+" must be a valid email address")
+		case "min":
+			problems = append(problems, fmt.Sprintf("%s must be at least %s characters", field, fe.Param()))
+		case "max":
+			problems = append(problems, fmt.Sprintf("%s must be at most %s characters", field, fe.Param()))
+		default:
+			problems = append(problems, fmt.Sprintf("%s failed %s validation", field, fe.Tag()))
+		}
+	}
+	return problems
+}
+
+var importFieldNames = map[string]string{
+	"FirstName": "first_name",
+	"LastName":  "last_name",
+	"Email":     "email",
+	"Password":  "password",
+	"IsAdmin":   "is_admin",
+}
+
+func (r *ImportReport) addError(row int, email string, problems ...string) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Row: row, Email: strings.TrimSpace(email), Errors: problems})
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/repository"
+)
+
+// readResult is what one call of importReader.next returned
+type readResult struct {
+	row     int
+	data    ImportUserRow
+	rowErr  string // message of an *importRowError
+	aborted bool   // any other error
+}
+
+// readAll calls next until io.EOF or an error that is not confined to a row
+func readAll(t *testing.T, reader importReader) []readResult {
+	t.Helper()
+	var results []readResult
+	for {
+		row, data, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return results
+		}
+		var rowErr *importRowError
+		switch {
+		case errors.As(err, &rowErr):
+			results = append(results, readResult{row: row, rowErr: rowErr.msg})
+		case err != nil:
+			return append(results, readResult{aborted: true})
+		default:
+			results = append(results, readResult{row: row, data: data})
+		}
+	}
+}
+
+func TestImportReader(t *testing.T) {
+	alice := ImportUserRow{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com", Password: "password1"}
+	admin := alice
+	admin.IsAdmin = true
+
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantErr error // of newImportReader
+		want    []readResult
+	}{
+		{
+			name:   "csv",
+			format: "csv",
+			input:  "first_name,last_name,email,password\nAlice,Smith,alice@example.com,password1\n",
+			want:   []readResult{{row: 1, data: alice}},
+		},
+		{
+			name:   "csv with reordered columns, BOM, spaces and is_admin",
+			format: "CSV",
+			input:  "\ufeffEmail, password ,first_name,last_name,is_admin\n alice@example.com ,password1,Alice,Smith,true\n",
+			want:   []readResult{{row: 1, data: admin}},
+		},
+		{
+			name:   "csv row errors",
+			format: "csv",
+			input:  "first_name,last_name,email,password,is_admin\nAlice,Smith,alice@example.com,password1,maybe\n\"Bob,Jones\nAlice,Smith,alice@example.com,password1,\n",
+			want: []readResult{
+				{row: 1, rowErr: "is_admin must be true or false"},
+				{row: 2, rowErr: `malformed csv: extraneous or missing " in quoted-field`},
+			},
+		},
+		{
+			name:   "csv short row",
+			format: "csv",
+			input:  "first_name,last_name,email,password\nAlice,Smith\n",
+			want:   []readResult{{row: 1, data: ImportUserRow{FirstName: "Alice", LastName: "Smith"}}},
+		},
+		{name: "csv without header", format: "csv", input: "", wantErr: ErrInvalidImport},
+		{name: "csv missing column", format: "csv", input: "first_name,last_name,email\n", wantErr: ErrInvalidImport},
+		{
+			name:   "ndjson",
+			format: "ndjson",
+			input:  "\n" + `{"first_name":" Alice ","last_name":"Smith","email":"alice@example.com","password":"password1","is_admin":true}` + "\n\n",
+			want:   []readResult{{row: 1, data: admin}},
+		},
+		{
+			name:   "ndjson row errors",
+			format: "jsonl",
+			input:  `{"first_name":"Alice","role":"admin"}` + "\n" + `{"first_name":` + "\n" + `{"first_name":"Alice","last_name":"Smith","email":"alice@example.com","password":"password1"}`,
+			want: []readResult{
+				{row: 1, rowErr: `malformed json: json: unknown field "role"`},
+				{row: 2, rowErr: "malformed json: unexpected EOF"},
+				{row: 3, data: alice},
+			},
+		},
+		{
+			name:   "ndjson line too long",
+			format: "ndjson",
+			input:  `{"first_name":"` + strings.Repeat("a", maxNDJSONLine) + `"}`,
+			want:   []readResult{{aborted: true}},
+		},
+		{name: "unknown format", format: "xlsx", input: "", wantErr: ErrUnsupportedImportFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := newImportReader(tt.format, strings.NewReader(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newImportReader = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := readAll(t, reader); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeImportUsers holds the emails of the users table and records the COPY batches
+type fakeImportUsers struct {
+	repository.UserRepository
+	mu       sync.Mutex
+	existing map[string]bool
+	copies   [][]string // emails of each CopyUsers call
+}
+
+func (r *fakeImportUsers) ListExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []string
+	for _, email := range emails {
+		if r.existing[email] {
+			found = append(found, email)
+		}
+	}
+	return found, nil
+}
+
+// CopyUsers fails the whole batch on a taken email, like COPY does
+func (r *fakeImportUsers) CopyUsers(ctx context.Context, arg []sqlc.CopyUsersParams) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	emails := make([]string, len(arg))
+	for i, p := range arg {
+		emails[i] = p.Email
+		if r.existing[p.Email] {
+			r.copies = append(r.copies, emails[:i+1])
+			return 0, uniqueViolation("users_email_key")
+		}
+	}
+	for _, email := range emails {
+		r.existing[email] = true
+	}
+	r.copies = append(r.copies, emails)
+	return int64(len(arg)), nil
+}
+
+func TestImportUsers_ReportsErrorRows(t *testing.T) {
+	repo := &fakeImportUsers{existing: map[string]bool{"taken@example.com": true}}
+	audit := &auditRecorder{}
+	svc := NewUserImportService(repo, audit, 1)
+
+	input := strings.Join([]string{
+		"first_name,last_name,email,password",
+		"Alice,Smith,alice@example.com,password1",
+		"Bob,,not-an-email,short",
+		"Carol,Jones,taken@example.com,password1",
+		"Alice,Again,alice@example.com,password1",
+		`"Dan,Brown`,
+	}, "\n")
+	report, err := svc.ImportUsers(context.Background(), strings.NewReader(input), ImportOptions{Format: "csv", DryRun: true, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("ImportUsers: %v", err)
+	}
+
+	want := ImportReport{
+		DryRun:   true,
+		Total:    5,
+		Imported: 1,
+		Failed:   4,
+		Errors: []ImportRowError{
+			{Row: 2, Email: "not-an-email", Errors: []string{"last_name is required", "email must be a valid email address", "password must be at least 8 characters"}},
+			// Rows 1 and 3 fill the first batch, which is checked against the table before row 4 is read
+			{Row: 3, Email: "taken@example.com", Errors: []string{"email already exists"}},
+			{Row: 4, Email: "alice@example.com", Errors: []string{"duplicate email, first used in row 1"}},
+			{Row: 5, Errors: []string{`malformed csv: extraneous or missing " in quoted-field`}},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v\nwant %+v", report, want)
+	}
+	if len(repo.copies) != 0 || len(audit.events) != 0 {
+		t.Errorf("a dry run copied %v and recorded %d audit events", repo.copies, len(audit.events))
+	}
+}
+
+func TestImportUsers_RetriesFailedCopyRowByRow(t *testing.T) {
+	repo := &fakeImportUsers{existing: map[string]bool{}}
+	audit := &auditRecorder{}
+	// The batch passes the existence check, then a concurrent signup takes one of its emails
+	svc := NewUserImportService(&racingImportUsers{fakeImportUsers: repo, takeBeforeCopy: "bob@example.com"}, audit, 2)
+
+	input := "{\"first_name\":\"Alice\",\"last_name\":\"Smith\",\"email\":\"alice@example.com\",\"password\":\"password1\"}\n" +
+		"{\"first_name\":\"Bob\",\"last_name\":\"Jones\",\"email\":\"bob@example.com\",\"password\":\"password2\"}\n"
+	report, err := svc.ImportUsers(context.Background(), strings.NewReader(input), ImportOptions{Format: "ndjson", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ImportUsers: %v", err)
+	}
+
+	if report.Imported != 1 || report.Failed != 1 || len(report.Errors) != 1 || report.Errors[0].Row != 2 {
+		t.Errorf("report = %+v, want alice imported and row 2 rejected", report)
+	}
+	wantCopies := [][]string{{"alice@example.com", "bob@example.com"}, {"alice@example.com"}, {"bob@example.com"}}
+	if !reflect.DeepEqual(repo.copies, wantCopies) {
+		t.Errorf("copies = %v, want %v", repo.copies, wantCopies)
+	}
+	if len(audit.events) != 1 || audit.events[0].Action != AuditUserImport || audit.events[0].Details["imported"] != 1 {
+		t.Errorf("audit events = %+v, want one import event counting 1 user", audit.events)
+	}
+}
+
+// racingImportUsers takes an email between the existence check and the COPY, like a concurrent signup
+type racingImportUsers struct {
+	*fakeImportUsers
+	takeBeforeCopy string
+}
+
+func (r *racingImportUsers) ListExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	found, err := r.fakeImportUsers.ListExistingEmails(ctx, emails)
+	r.mu.Lock()
+	r.existing[r.takeBeforeCopy] = true
+	r.mu.Unlock()
+	return found, err
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// userLoader merges concurrent single-user lookups into batched queries, DataLoader style.
+// The first lookup opens a batch; every lookup arriving within wait joins it, and the batch
+// is sent as one query when the window closes or maxBatch distinct ids have been collected.
+type userLoader struct {
+	fetch    func(ctx context.Context, ids []int64) ([]sqlc.User, error)
+	wait     time.Duration
+	maxBatch int
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	pending *userBatch
+}
+
+// userBatch is one in-flight group of lookups; users and err are set before done is closed
+type userBatch struct {
+	links []trace.Link // the spans of the callers, each from a different request
+	ids   []int64
+	index map[int64]struct{}
+	timer *time.Timer
+	done  chan struct{}
+	users map[int64]sqlc.User
+	err   error
+}
+
+func newUserLoader(fetch func(ctx context.Context, ids []int64) ([]sqlc.User, error), wait time.Duration, maxBatch int, timeout time.Duration) *userLoader {
+	if maxBatch <= 0 {
+		maxBatch = 100
+	}
+	return &userLoader{fetch: fetch, wait: wait, maxBatch: maxBatch, timeout: timeout}
+}
+
+// Load returns the user with the given id, or a NotFound error like a single-row query would.
+// A cancelled ctx only abandons this caller's wait; the shared batch still runs for the others.
+func (l *userLoader) Load(ctx context.Context, id int64) (sqlc.User, error) {
+	l.mu.Lock()
+	b := l.pending
+	if b == nil {
+		b = &userBatch{
+			index: make(map[int64]struct{}),
+			done:  make(chan struct{}),
+		}
+		l.pending = b
+		b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		b.links = append(b.links, trace.Link{SpanContext: sc})
+	}
+	if _, ok := b.index[id]; !ok {
+		b.index[id] = struct{}{}
+		b.ids = append(b.ids, id)
+	}
+	full := len(b.ids) >= l.maxBatch
+	if full {
+		l.pending = nil
+		b.timer.Stop()
+	}
+	l.mu.Unlock()
+
+	if full {
+		go l.run(b)
+	}
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return sqlc.User{}, ctx.Err()
+	}
+	if b.err != nil {
+		return sqlc.User{}, b.err
+	}
+	user, ok := b.users[id]
+	if !ok {
+		return sqlc.User{}, apperror.Wrap(apperror.NotFound, "user not found", pgx.ErrNoRows)
+	}
+	return user, nil
+}
+
+// dispatch runs b when its window closes, unless it was already sent because it filled up
+func (l *userLoader) dispatch(b *userBatch) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+	l.run(b)
+}
+
+// run queries the users of b. The batch serves several requests and outlives the one that opened it,
+// so it starts from a fresh context: its queries carry no request ID, logger or parent span of a single
+// caller. Its own span links to the spans of all callers instead. Without a caller's deadline, the
+// query is bounded by the loader's timeout.
+func (l *userLoader) run(b *userBatch) {
+	defer close(b.done)
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	ctx, span := tracing.Tracer().Start(ctx, "userLoader.batch",
+		trace.WithLinks(b.links...),
+		trace.WithAttributes(attribute.Int("batch.size", len(b.ids))),
+	)
+	defer span.End()
+	users, err := l.fetch(ctx, b.ids)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.users = make(map[int64]sqlc.User, len(users))
+	for _, u := range users {
+		b.users[u.ID] = u
+	}
+}
@@ -2,34 +2,232 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yourusername/yourprojectname/db/sqlc"
+	"github.com/yourusername/yourprojectname/internal/apperror"
+	"github.com/yourusername/yourprojectname/internal/metrics"
 	"github.com/yourusername/yourprojectname/internal/repository"
 )
 
+// errUserDidNotExist is returned by GetUserAsOf for times outside the user's lifetime.
+var errUserDidNotExist = apperror.New(apperror.NotFound, "user did not exist at that time")
+
+// UserServiceConfig controls how single-user lookups are coalesced.
+type UserServiceConfig struct {
+	BatchWindow time.Duration // how long a lookup waits for others to join its batch; 0 disables coalescing
+	MaxBatch    int           // a batch is sent early once it holds this many ids
+	Timeout     time.Duration // bounds the query of a batch, which no request deadline covers
+}
+
+// BatchGetResult holds the users found by a batch lookup and the keys that matched nothing.
+// Users keeps the order of the requested keys, IDs before emails.
+type BatchGetResult struct {
+	Users         []sqlc.User
+	MissingIDs    []int64
+	MissingEmails []string
+}
+
 // UserService defines the interface for user-related business logic.
 type UserService interface {
 	CreateUser(ctx context.Context, params sqlc.CreateUserParams) (sqlc.User, error)
+	UpdateUser(ctx context.Context, params sqlc.UpdateUserParams) (sqlc.User, error)
+	SetUserAdmin(ctx context.Context, id int64, isAdmin bool) (sqlc.User, error)
+	DeleteUser(ctx context.Context, id int64) error
 	GetUserByID(ctx context.Context, id int64) (sqlc.User, error)
+	GetUserAsOf(ctx context.Context, id int64, asOf time.Time) (sqlc.User, error)
+	ListUserHistory(ctx context.Context, id int64) ([]sqlc.UserHistory, error)
+	BatchGetUsers(ctx context.Context, ids []int64, emails []string) (BatchGetResult, error)
+	ListUsers(ctx context.Context, params sqlc.ListUsersParams) ([]sqlc.User, error)
 }
 
 type userServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	auditLogger AuditLogger
+	loader      *userLoader
+	signups     prometheus.Counter
 }
 
 // NewUserService creates a new instance of UserService.
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userServiceImpl{
-		userRepo: userRepo,
+// Every create, update and delete is recorded with auditLogger.
+func NewUserService(userRepo repository.UserRepository, auditLogger AuditLogger, registry *metrics.Registry, cfg UserServiceConfig) UserService {
+	s := &userServiceImpl{
+		userRepo:    userRepo,
+		auditLogger: auditLogger,
+		signups:     registry.NewCounterVec("users", "signups_total", "Users created through the API.").WithLabelValues(),
+	}
+	if cfg.BatchWindow > 0 {
+		s.loader = newUserLoader(userRepo.GetUsersByIDs, cfg.BatchWindow, cfg.MaxBatch, cfg.Timeout)
 	}
+	return s
 }
 
 // CreateUser creates a new user.
 func (s *userServiceImpl) CreateUser(ctx context.Context, params sqlc.CreateUserParams) (sqlc.User, error) {
-	return s.userRepo.CreateUser(ctx, params)
+	user, err := s.userRepo.CreateUser(ctx, params)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserCreate, TargetType: "user", TargetID: user.ID, After: user})
+	s.signups.Inc()
+	return user, nil
+}
+
+// UpdateUser changes the name and/or password of a user; unset fields are kept.
+func (s *userServiceImpl) UpdateUser(ctx context.Context, params sqlc.UpdateUserParams) (sqlc.User, error) {
+	// Read the row directly; a coalesced lookup could return a snapshot older than this request
+	before, err := s.userRepo.GetUserByID(ctx, params.ID)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	user, err := s.userRepo.UpdateUser(ctx, params)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserUpdate, TargetType: "user", TargetID: user.ID, Before: before, After: user})
+	return user, nil
+}
+
+// SetUserAdmin grants or revokes the admin role of a user.
+func (s *userServiceImpl) SetUserAdmin(ctx context.Context, id int64, isAdmin bool) (sqlc.User, error) {
+	before, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	user, err := s.userRepo.SetUserAdmin(ctx, sqlc.SetUserAdminParams{IsAdmin: isAdmin, ID: id})
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserUpdate, TargetType: "user", TargetID: user.ID, Before: before, After: user})
+	return user, nil
+}
+
+// DeleteUser deletes a user.
+func (s *userServiceImpl) DeleteUser(ctx context.Context, id int64) error {
+	before, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.auditLogger.Record(ctx, AuditEvent{Action: AuditUserDelete, TargetType: "user", TargetID: id, Before: before})
+	return nil
 }
 
 // GetUserByID retrieves a user by their ID.
+// Concurrent calls are merged into one query when coalescing is enabled.
 func (s *userServiceImpl) GetUserByID(ctx context.Context, id int64) (sqlc.User, error) {
+	if s.loader != nil {
+		return s.loader.Load(ctx, id)
+	}
 	return s.userRepo.GetUserByID(ctx, id)
 }
+
+// GetUserAsOf reconstructs a user as it was at asOf. The password hash is never part of the result.
+// It returns a NotFound error if the user did not exist at that time.
+func (s *userServiceImpl) GetUserAsOf(ctx context.Context, id int64, asOf time.Time) (sqlc.User, error) {
+	version, err := s.userRepo.GetUserHistoryAsOf(ctx, sqlc.GetUserHistoryAsOfParams{UserID: id, AsOf: asOf})
+	if err == nil {
+		return sqlc.User{
+			ID:        version.UserID,
+			FirstName: version.FirstName,
+			LastName:  version.LastName,
+			Email:     version.Email,
+			CreatedAt: version.CreatedAt,
+			UpdatedAt: version.UpdatedAt,
+			AvatarKey: version.AvatarKey,
+			IsAdmin:   version.IsAdmin,
+			Locale:    version.Locale,
+		}, nil
+	}
+	if !errors.Is(err, apperror.NotFound) {
+		return sqlc.User{}, err
+	}
+
+	// No prior version covers asOf, so it is either the current version or before the user existed
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if errors.Is(err, apperror.NotFound) {
+		// Deleted before asOf; the delete would have left a version covering earlier times
+		return sqlc.User{}, errUserDidNotExist
+	}
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if asOf.Before(user.CreatedAt) {
+		return sqlc.User{}, errUserDidNotExist
+	}
+	user.HashedPassword = ""
+	return user, nil
+}
+
+// ListUserHistory returns the prior versions of a user, newest first.
+// Versions of deleted users are kept, so this works after DeleteUser as well.
+func (s *userServiceImpl) ListUserHistory(ctx context.Context, id int64) ([]sqlc.UserHistory, error) {
+	return s.userRepo.ListUserHistory(ctx, id)
+}
+
+// BatchGetUsers looks up users by ID and by email with one query per key type.
+// Duplicate keys are reported once, at the position of their first occurrence.
+func (s *userServiceImpl) BatchGetUsers(ctx context.Context, ids []int64, emails []string) (BatchGetResult, error) {
+	result := BatchGetResult{Users: []sqlc.User{}, MissingIDs: []int64{}, MissingEmails: []string{}}
+
+	ids = dedupe(ids)
+	if len(ids) > 0 {
+		users, err := s.userRepo.GetUsersByIDs(ctx, ids)
+		if err != nil {
+			return BatchGetResult{}, err
+		}
+		byID := make(map[int64]sqlc.User, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+		for _, id := range ids {
+			if u, ok := byID[id]; ok {
+				result.Users = append(result.Users, u)
+			} else {
+				result.MissingIDs = append(result.MissingIDs, id)
+			}
+		}
+	}
+
+	emails = dedupe(emails)
+	if len(emails) > 0 {
+		users, err := s.userRepo.GetUsersByEmails(ctx, emails)
+		if err != nil {
+			return BatchGetResult{}, err
+		}
+		byEmail := make(map[string]sqlc.User, len(users))
+		for _, u := range users {
+			byEmail[u.Email] = u
+		}
+		for _, email := range emails {
+			if u, ok := byEmail[email]; ok {
+				result.Users = append(result.Users, u)
+			} else {
+				result.MissingEmails = append(result.MissingEmails, email)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ListUsers retrieves a page of users matching the filters in params.
+func (s *userServiceImpl) ListUsers(ctx context.Context, params sqlc.ListUsersParams) ([]sqlc.User, error) {
+	return s.userRepo.ListUsers(ctx, params)
+}
+
+func dedupe[T comparable](keys []T) []T {
+	seen := make(map[T]struct{}, len(keys))
+	out := keys[:0:0]
+	for _, k := range keys {
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			out = append(out, k)
+		}
+	}
+	return out
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+// ErrBlobNotFound indicates that no blob is stored under the requested key.
+var ErrBlobNotFound = apperror.New(apperror.NotFound, "file not found")
+
+// ErrInvalidSignature indicates that a signed URL is malformed, tampered with or expired.
+var ErrInvalidSignature = apperror.New(apperror.Forbidden, "invalid or expired download link")
+
+// BlobStore defines methods for storing binary objects such as avatars
+type BlobStore interface {
+	// Put stores the content of r under key. size may be -1 if unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key. The caller must close the returned reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a download URL for key that stops working after ttl.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
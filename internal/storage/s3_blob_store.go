@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/yourusername/yourprojectname/internal/tracing"
+)
+
+// S3Config holds the connection settings for an S3-compatible object store (AWS S3, MinIO, ...)
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey func() string // called per request, so rotated keys take effect without a restart
+	SecretKey func() string
+	UseSSL    bool
+}
+
+// rotatingCredentials hands the current keys to the client for every request
+type rotatingCredentials struct {
+	accessKey func() string
+	secretKey func() string
+}
+
+func (r rotatingCredentials) Retrieve() (credentials.Value, error) {
+	return credentials.Value{
+		AccessKeyID:     r.accessKey(),
+		SecretAccessKey: r.secretKey(),
+		SignerType:      credentials.SignatureV4,
+	}, nil
+}
+
+func (r rotatingCredentials) IsExpired() bool {
+	return true
+}
+
+// S3BlobStore stores blobs in an S3-compatible bucket.
+// Downloads use presigned GET URLs, so they never hit the application.
+type S3BlobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3BlobStore creates a new instance of S3BlobStore and makes sure the bucket exists
+func NewS3BlobStore(ctx context.Context, cfg S3Config) (*S3BlobStore, error) {
+	transport, err := minio.DefaultTransport(cfg.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create S3 transport: %w", err)
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:     credentials.New(rotatingCredentials{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey}),
+		Secure:    cfg.UseSSL,
+		Region:    cfg.Region,
+		Transport: tracing.Transport(transport),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create S3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check S3 bucket: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("unable to create S3 bucket: %w", err)
+		}
+	}
+
+	return &S3BlobStore{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Put uploads a blob
+func (s *S3BlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get downloads a blob
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	// GetObject is lazy, so stat first to surface a missing key as ErrBlobNotFound
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+// Delete removes a blob
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// SignedURL returns a presigned GET URL
+func (s *S3BlobStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
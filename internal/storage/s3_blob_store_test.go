@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a minimal in-memory stand-in for MinIO, serving the path-style requests the client sends to an IP endpoint
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string][]byte // by "bucket/key"
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	f := &fakeS3{buckets: make(map[string]bool), objects: make(map[string][]byte), types: make(map[string]string)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if key == "" {
+		switch r.Method {
+		case http.MethodHead:
+			if !f.buckets[bucket] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			f.buckets[bucket] = true
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+		return
+	}
+	if !f.buckets[bucket] {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	path := bucket + "/" + key
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readS3Payload(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[path] = body
+		f.types[path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"fake"`)
+	case http.MethodHead, http.MethodGet:
+		body, ok := f.objects[path]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[path])
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"fake"`)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (f *fakeS3) hasBucket(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buckets[name]
+}
+
+func (f *fakeS3) object(path string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, ok := f.objects[path]
+	return body, ok
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+// readS3Payload returns the object content, decoding the aws-chunked encoding of streaming signatures
+func readS3Payload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var out bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return out.Bytes(), nil
+		}
+		if _, err := io.CopyN(&out, br, size); err != nil {
+			return nil, err
+		}
+		if _, err := br.ReadString('\n'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3BlobStore(t *testing.T) (*S3BlobStore, *fakeS3) {
+	t.Helper()
+	fake, srv := newFakeS3(t)
+	store, err := NewS3BlobStore(context.Background(), S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "avatars",
+		AccessKey: func() string { return "minioadmin" },
+		SecretKey: func() string { return "minioadmin" },
+	})
+	if err != nil {
+		t.Fatalf("NewS3BlobStore: %v", err)
+	}
+	return store, fake
+}
+
+func TestS3BlobStore_CreatesBucket(t *testing.T) {
+	_, fake := newTestS3BlobStore(t)
+	if !fake.hasBucket("avatars") {
+		t.Fatal("bucket was not created")
+	}
+}
+
+func TestS3BlobStore_PutGetDelete(t *testing.T) {
+	store, fake := newTestS3BlobStore(t)
+	ctx := context.Background()
+	content := []byte("fake image data")
+
+	if err := store.Put(ctx, "users/1/small.jpg", bytes.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got, ok := fake.object("avatars/users/1/small.jpg"); !ok || !bytes.Equal(got, content) {
+		t.Fatalf("stored object = %q, %v; want %q", got, ok, content)
+	}
+
+	rc, err := store.Get(ctx, "users/1/small.jpg")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("reading blob: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	if err := store.Delete(ctx, "users/1/small.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.object("avatars/users/1/small.jpg"); ok {
+		t.Error("object still stored after Delete")
+	}
+	// Deleting a missing key is not an error
+	if err := store.Delete(ctx, "users/1/small.jpg"); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+}
+
+func TestS3BlobStore_GetMissing(t *testing.T) {
+	store, _ := newTestS3BlobStore(t)
+
+	_, err := store.Get(context.Background(), "users/2/missing.jpg")
+	if !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("Get of a missing key = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestS3BlobStore_SignedURL(t *testing.T) {
+	store, _ := newTestS3BlobStore(t)
+
+	u, err := store.SignedURL(context.Background(), "users/1/small.jpg", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	if !strings.Contains(u, "/avatars/users/1/small.jpg") || !strings.Contains(u, "X-Amz-Expires=60") {
+		t.Errorf("SignedURL = %s, want a presigned URL for the key expiring in 60s", u)
+	}
+}
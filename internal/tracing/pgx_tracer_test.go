@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestPgxTracer_Query(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		err       error
+		wantName  string
+		wantError bool
+	}{
+		{
+			name:     "sqlc statement",
+			sql:      "-- name: GetUserByID :one\nSELECT id FROM users WHERE id = $1",
+			wantName: "GetUserByID",
+		},
+		{
+			name:     "hand-written query",
+			sql:      "  lock table audit_events_default in access exclusive mode",
+			wantName: "LOCK",
+		},
+		{
+			name:     "no rows",
+			sql:      "-- name: GetUserByEmail :one\nSELECT id FROM users WHERE email = $1",
+			err:      pgx.ErrNoRows,
+			wantName: "GetUserByEmail",
+		},
+		{
+			name:      "failed query",
+			sql:       "-- name: CreateUser :one\nINSERT INTO users DEFAULT VALUES",
+			err:       &pgconn.PgError{Code: "23505", Message: "duplicate key value"},
+			wantName:  "CreateUser",
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+			tracer := NewPgxTracer()
+			parentCtx, parent := Tracer().Start(context.Background(), "request")
+
+			ctx := tracer.TraceQueryStart(parentCtx, nil, pgx.TraceQueryStartData{SQL: tt.sql})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: tt.err})
+
+			span := endedSpan(t, recorder)
+			if span.Name() != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name(), tt.wantName)
+			}
+			if span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("span kind = %v, want client", span.SpanKind())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Error("query span is not a child of the span in the context")
+			}
+			if v, _ := spanAttribute(span, semconv.DBSystemKey); v.AsString() != "postgresql" {
+				t.Errorf("db.system = %q, want postgresql", v.AsString())
+			}
+			if v, _ := spanAttribute(span, semconv.DBQueryTextKey); v.AsString() != tt.sql {
+				t.Errorf("db.query.text = %q, want the statement", v.AsString())
+			}
+			if v, _ := spanAttribute(span, "db.rows_affected"); v.AsInt64() != 1 {
+				t.Errorf("db.rows_affected = %d, want 1", v.AsInt64())
+			}
+
+			wantCode := codes.Unset
+			if tt.wantError {
+				wantCode = codes.Error
+			}
+			if span.Status().Code != wantCode {
+				t.Errorf("span status = %v, want %v", span.Status().Code, wantCode)
+			}
+			if recorded := len(span.Events()) > 0; recorded != tt.wantError {
+				t.Errorf("error recorded = %v, want %v", recorded, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPgxTracer_CopyFrom(t *testing.T) {
+	recorder := newRecorder(t)
+	tracer := NewPgxTracer()
+
+	ctx := tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{
+		TableName:   pgx.Identifier{"users"},
+		ColumnNames: []string{"first_name", "last_name", "email"},
+	})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 500"), Err: errors.New("conn closed")})
+
+	span := endedSpan(t, recorder)
+	if span.Name() != `COPY "users"` {
+		t.Errorf("span name = %q, want %q", span.Name(), `COPY "users"`)
+	}
+	if v, _ := spanAttribute(span, semconv.DBCollectionNameKey); v.AsString() != `"users"` {
+		t.Errorf("db.collection.name = %q, want %q", v.AsString(), `"users"`)
+	}
+	if v, _ := spanAttribute(span, "db.rows_affected"); v.AsInt64() != 500 {
+		t.Errorf("db.rows_affected = %d, want 500", v.AsInt64())
+	}
+	if span.Status().Code != codes.Error || span.Status().Description != "conn closed" {
+		t.Errorf("span status = %+v, want an error with the cause", span.Status())
+	}
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedisHook_Command(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantError bool
+	}{
+		{name: "success"},
+		{name: "missing key", err: redis.Nil},
+		{name: "failure", err: errors.New("READONLY You can't write against a read only replica"), wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+			hook := NewRedisHook()
+			cmd := redis.NewStringCmd(context.Background(), "get", "refresh_token:secret")
+
+			ctx, err := hook.BeforeProcess(context.Background(), cmd)
+			if err != nil {
+				t.Fatalf("BeforeProcess: %v", err)
+			}
+			cmd.SetErr(tt.err)
+			if err := hook.AfterProcess(ctx, cmd); err != nil {
+				t.Fatalf("AfterProcess: %v", err)
+			}
+
+			span := endedSpan(t, recorder)
+			if span.Name() != "GET" {
+				t.Errorf("span name = %q, want GET", span.Name())
+			}
+			if span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("span kind = %v, want client", span.SpanKind())
+			}
+			if v, _ := spanAttribute(span, semconv.DBSystemKey); v.AsString() != "redis" {
+				t.Errorf("db.system = %q, want redis", v.AsString())
+			}
+			// Arguments may be tokens and must not be recorded
+			for _, kv := range span.Attributes() {
+				if kv.Value.Emit() == "refresh_token:secret" {
+					t.Errorf("attribute %s records a command argument", kv.Key)
+				}
+			}
+
+			wantCode := codes.Unset
+			if tt.wantError {
+				wantCode = codes.Error
+			}
+			if span.Status().Code != wantCode {
+				t.Errorf("span status = %v, want %v", span.Status().Code, wantCode)
+			}
+		})
+	}
+}
+
+func TestRedisHook_Pipeline(t *testing.T) {
+	recorder := newRecorder(t)
+	hook := NewRedisHook()
+	cmds := []redis.Cmder{
+		redis.NewStringCmd(context.Background(), "get", "a"),
+		redis.NewIntCmd(context.Background(), "incr", "b"),
+		redis.NewBoolCmd(context.Background(), "expire", "b", 60),
+	}
+
+	ctx, err := hook.BeforeProcessPipeline(context.Background(), cmds)
+	if err != nil {
+		t.Fatalf("BeforeProcessPipeline: %v", err)
+	}
+	cmds[0].SetErr(redis.Nil)
+	cmds[1].SetErr(errors.New("ERR value is not an integer"))
+	if err := hook.AfterProcessPipeline(ctx, cmds); err != nil {
+		t.Fatalf("AfterProcessPipeline: %v", err)
+	}
+
+	span := endedSpan(t, recorder)
+	if span.Name() != "PIPELINE" {
+		t.Errorf("span name = %q, want PIPELINE", span.Name())
+	}
+	if v, _ := spanAttribute(span, "db.redis.commands"); !slices.Equal(v.AsStringSlice(), []string{"get", "incr", "expire"}) {
+		t.Errorf("db.redis.commands = %v, want [get incr expire]", v.AsStringSlice())
+	}
+	// The missing key of the first command is not a failure, the error of the second is
+	if span.Status().Code != codes.Error || span.Status().Description != "ERR value is not an integer" {
+		t.Errorf("span status = %+v, want the error of incr", span.Status())
+	}
+}
@@ -0,0 +1,84 @@
+// Package tracing sets up OpenTelemetry tracing and instruments pgx, go-redis and outgoing HTTP requests.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans this application creates itself
+const instrumentationName = "github.com/yourusername/yourprojectname"
+
+// Options configures Setup
+type Options struct {
+	Exporter    string // otlp, stdout or none
+	Endpoint    string // OTLP/HTTP collector URL, used by the otlp exporter
+	ServiceName string
+	Environment string  // deployment.environment resource attribute
+	SampleRatio float64 // share of new traces recorded; the decision of a sampled caller is always followed
+}
+
+// Setup installs the global tracer provider and the W3C trace context and baggage propagators.
+// With the none exporter only the propagators are installed, so incoming trace context still reaches
+// outgoing requests. The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "none":
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	provider := NewTracerProvider(sdktrace.NewBatchSpanProcessor(exporter), opts)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider sending spans to processor; tests pass a
+// sdktrace.NewSimpleSpanProcessor around a tracetest.InMemoryExporter.
+func NewTracerProvider(processor sdktrace.SpanProcessor, opts Options) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(opts.ServiceName),
+			semconv.DeploymentEnvironment(opts.Environment),
+		)),
+	)
+}
+
+// Tracer returns the tracer of the global provider for the application's own spans
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Transport wraps base, or http.DefaultTransport if it is nil, to create a client span for every
+// outgoing request and inject the trace context into its headers.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,235 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+
+	"github.com/yourusername/yourprojectname/internal/apperror"
+)
+
+// ErrUnsupportedImageType indicates that the uploaded content is not an accepted image format.
+var ErrUnsupportedImageType = apperror.New(apperror.Unsupported, "image must be a JPEG, PNG, GIF or WebP file")
+
+// ErrImageTooLarge indicates that the image exceeds the allowed byte size or pixel dimensions.
+var ErrImageTooLarge = apperror.New(apperror.TooLarge, "image exceeds the maximum upload size")
+
+const (
+	// Decoding allocates width*height*4 bytes, so cap dimensions independently of the byte size
+	maxImagePixels = 40_000_000
+	jpegQuality    = 85
+)
+
+// allowedImageTypes maps sniffed content types to the format name used by image.Decode
+var allowedImageTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// ImageVariant describes one rendition of an uploaded image.
+// A Size of 0 keeps the original dimensions (bounded by MaxSize); otherwise the image is center-cropped to a square.
+type ImageVariant struct {
+	Name    string
+	Size    int
+	MaxSize int
+}
+
+// EncodedImage is a processed rendition, ready to be stored
+type EncodedImage struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ProcessImage validates, decodes and re-encodes an image into the requested variants.
+// Re-encoding from decoded pixels drops all metadata (EXIF, GPS, ICC, comments);
+// the EXIF orientation is applied to the pixels first so photos keep their rotation.
+// JPEG input is encoded as JPEG; every other format is encoded as PNG to keep transparency.
+func ProcessImage(data []byte, variants []ImageVariant) ([]EncodedImage, error) {
+	contentType := http.DetectContentType(data)
+	format, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImageType, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImageType, err)
+	}
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	results := make([]EncodedImage, 0, len(variants))
+	for _, v := range variants {
+		var img image.Image
+		if v.Size > 0 {
+			img = resizeSquare(src, v.Size)
+		} else {
+			img = resizeToFit(src, v.MaxSize)
+		}
+
+		var buf bytes.Buffer
+		encoded := EncodedImage{Name: v.Name}
+		if format == "jpeg" {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+			encoded.ContentType, encoded.Extension = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, img)
+			encoded.ContentType, encoded.Extension = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", v.Name, err)
+		}
+		encoded.Data = buf.Bytes()
+		results = append(results, encoded)
+	}
+	return results, nil
+}
+
+// resizeToFit scales src down so neither side exceeds maxSize. Smaller images are only re-drawn.
+func resizeToFit(src image.Image, maxSize int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxSize > 0 && (w > maxSize || h > maxSize) {
+		if w >= h {
+			w, h = maxSize, max(1, h*maxSize/w)
+		} else {
+			w, h = max(1, w*maxSize/h), maxSize
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+// resizeSquare center-crops src to a square and scales it to size x size
+func resizeSquare(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, image.Rect(x0, y0, x0+side, y0+side), draw.Src, nil)
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag (1-8) of a JPEG, or 1 if absent or unreadable
+func jpegOrientation(data []byte) int {
+	r := bytes.NewReader(data)
+	var marker [2]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil || marker != [2]byte{0xFF, 0xD8} {
+		return 1
+	}
+	for {
+		if _, err := io.ReadFull(r, marker[:]); err != nil || marker[0] != 0xFF {
+			return 1
+		}
+		var segLen uint16
+		if err := binary.Read(r, binary.BigEndian, &segLen); err != nil || segLen < 2 {
+			return 1
+		}
+		seg := make([]byte, segLen-2)
+		if _, err := io.ReadFull(r, seg); err != nil {
+			return 1
+		}
+		// APP1 segment carrying EXIF
+		if marker[1] == 0xE1 && len(seg) > 14 && string(seg[:6]) == "Exif\x00\x00" {
+			return exifOrientation(seg[6:])
+		}
+		// Start of scan: no more metadata segments follow
+		if marker[1] == 0xDA {
+			return 1
+		}
+	}
+}
+
+// exifOrientation reads the orientation tag (0x0112) from the first IFD of a TIFF structure
+func exifOrientation(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation transforms src so it displays upright without the EXIF orientation tag
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
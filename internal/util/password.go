@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/yourusername/yourprojectname/internal/metrics"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -32,6 +34,7 @@ func HashPassword(password string) (string, error) {
 	if password == "" {
 		return "", errors.New("password cannot be empty")
 	}
+	defer metrics.ObservePasswordHash("hash", time.Now())
 
 	salt := make([]byte, passwordSaltBytes)
 	if _, err := rand.Read(salt); err != nil {
@@ -81,6 +84,7 @@ func CheckPasswordHash(password, storedHash string) (bool, error) {
 	}
 
 	// Verify the password
+	defer metrics.ObservePasswordHash("verify", time.Now())
 	comparisonHash := pbkdf2.Key([]byte(password), salt, iterations, len(hash), sha256.New)
 
 	// Constant time comparison to prevent timing attacks